@@ -0,0 +1,348 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "reflect"
+    "strings"
+    "unicode"
+)
+
+// Process populates the struct pointed to by spec from the environment.
+//
+// Each exported field is read from the environment variable named by its
+// "env" tag or, if the tag is missing, by its field name split at word
+// boundaries (ReadTimeout becomes READ_TIMEOUT). The key is normalized and
+// prefixed like every other key of e. Fields tagged with `env:"-"` are
+// skipped.
+//
+// Fields of type Secret or tagged with `secret:"true"` mark their key as
+// secret, see MarkSecret.
+//
+// Nested structs are processed recursively. The keys of their fields are
+// prefixed with the name of the nested field, so field Host of field
+// PrimaryDB is read from PRIMARY_DB_HOST. The "env" tag of the nested field
+// replaces this sub-prefix. Embedded structs and fields tagged with
+// `flatten:"true"` add no sub-prefix. Validate methods of nested structs are
+// called as well, their errors are reported as problems. A nil pointer to a
+// struct is only allocated if at least one variable of the struct is set, so
+// optional sub-configurations like DB *DatabaseConfig stay nil otherwise.
+//
+// Slices of structs are read from indexed keys, so the Host of the second
+// element of field Servers is read from SERVERS_1_HOST, maps from string to
+// structs from named keys like SERVERS_EU_HOST with the name as map key.
+// Map keys are taken from the keys as they are, so they are normalized like
+// keys: the map key eu written by Marshal is read back as EU.
+// The elements are discovered by scanning the Source, which must implement
+// Lister. Gaps in the indices are reported as *IndexError. Collections
+// without any matching key are left untouched. Collections may be nested,
+// e.g. SERVERS_1_PORTS_0_NUMBER.
+//
+// If a variable is not set, the value of the "default" tag is used instead.
+// A field tagged with `required:"true"` causes an error if neither the
+// variable nor a default is available. Fields without any value are left
+// untouched.
+//
+// Supported field types are all registered types (see Register), types
+// implementing encoding.TextUnmarshaler, slices of them and maps from string
+// to them, which are split according to the ListOptions of e.
+//
+// Values, including defaults, are checked against the rules added with
+// AddRules and the rules declared by the tags "min", "max", "oneof" (space
+// separated values), "pattern", "minlen", "maxlen" and `nonzero:"true"`,
+// see the Rule constructors of the same names.
+//
+// All fields are processed even if some of them fail; the returned
+// *ValidationError lists every missing required variable (matching
+// ErrNotSet), every malformed one (a *ParseError) and every violated rule
+// (a *RuleError). Fields which fail are left untouched. The "example" tag,
+// or the default, is shown in its report. The "usage" tag describes the
+// variable in the output of PrintUsage.
+//
+// Requirements depending on other fields are declared with the tags
+// "required_if" and "required_unless", which take pairs of a field name and
+// a value (`required_if:"TLSEnabled true"`), and "excluded_with", which
+// takes field names. Violations are reported as *RuleError, missing
+// variables match ErrNotSet.
+//
+// If all fields are valid and spec implements a Validate() error method, it
+// is called last and its error is returned unchanged.
+func (e *Env) Process(spec interface{}) error {
+    rv := reflect.ValueOf(spec)
+    if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+        return errors.New("Process requires a non-nil pointer to a struct")
+    }
+
+    rv = rv.Elem()
+    if problems := e.processStruct(rv, ""); len(problems) > 0 {
+        return &ValidationError{Problems: problems}
+    }
+
+    if v, ok := rv.Addr().Interface().(specValidator); ok {
+        return v.Validate()
+    }
+
+    return nil
+}
+
+// processStruct populates the fields of the struct rv, prepending prefix to
+// the names of all variables, and returns all problems.
+func (e *Env) processStruct(rv reflect.Value, prefix string) []Problem {
+    rt := rv.Type()
+
+    var (
+        problems []Problem
+        bound    []*boundField
+        fields   = make(map[string]*boundField)
+    )
+    for i := 0; i < rt.NumField(); i++ {
+        field := rt.Field(i)
+        if skipField(field) {
+            continue
+        }
+
+        if sub, ok := nestedPrefix(field, prefix); ok {
+            problems = append(problems, e.processNested(rv.Field(i), sub)...)
+            continue
+        }
+        if isCollection(field.Type) {
+            sub := joinPrefix(prefix, fieldName(field))
+            problems = append(problems, e.processCollection(rv.Field(i), sub)...)
+            continue
+        }
+
+        v, ok := e.fieldVariable(field, prefix)
+        if !ok {
+            continue
+        }
+        name, key := v.Name, v.Key
+        if v.Secret {
+            e.mu.Lock()
+            e.markSecretKey(key)
+            e.mu.Unlock()
+        }
+
+        example, ok := field.Tag.Lookup("example")
+        if !ok {
+            example = v.Default
+        }
+        bf := &boundField{field: field, value: rv.Field(i),
+            problem: Problem{Key: key, Type: v.Type, Example: e.redactExample(key, example)}}
+        bound = append(bound, bf)
+        fields[field.Name] = bf
+        fail := func(err error) {
+            bf.failed = true
+            problems = append(problems, bf.problemWith(err))
+        }
+
+        str, err := e.lookup(name)
+        if err != nil && !errors.Is(err, ErrNotSet) {
+            fail(err)
+            continue
+        }
+
+        bf.present = err == nil
+        ok = bf.present
+        if !ok {
+            str, ok = field.Tag.Lookup("default")
+        }
+        bf.str, bf.valued = str, ok
+        if !ok {
+            if v.Required {
+                fail(&notSetError{key})
+            }
+            continue
+        }
+
+        value, err := e.parseValue(field.Type, str)
+        if err != nil {
+            fail(e.parseError(key, str, v.Type, err))
+            continue
+        }
+        if err := e.checkRules(key, str, value, tagRules(field.Tag)); err != nil {
+            fail(err)
+            continue
+        }
+        bf.value.Set(value)
+    }
+
+    return append(problems, e.checkConditions(bound, fields)...)
+}
+
+// processNested populates the nested struct fv or the struct fv points to.
+// If all its fields are valid, its Validate method is called, if any and
+// accessible, which is not the case for embedded structs of unexported
+// types. A nil pointer is only allocated if any variable of the struct is
+// set.
+func (e *Env) processNested(fv reflect.Value, prefix string) []Problem {
+    if fv.Kind() == reflect.Ptr {
+        if !fv.IsNil() {
+            return e.processNested(fv.Elem(), prefix)
+        }
+        if !e.anySet(fv.Type().Elem(), prefix) {
+            return nil
+        }
+
+        elem := reflect.New(fv.Type().Elem())
+        problems := e.processNested(elem.Elem(), prefix)
+        if len(problems) == 0 {
+            fv.Set(elem)
+        }
+        return problems
+    }
+
+    if problems := e.processStruct(fv, prefix); len(problems) > 0 {
+        return problems
+    }
+
+    if !fv.Addr().CanInterface() {
+        return nil
+    }
+    if v, ok := fv.Addr().Interface().(specValidator); ok {
+        if err := v.Validate(); err != nil {
+            return []Problem{{Key: e.prepareKey(prefix), Type: fv.Type().String(), Err: err}}
+        }
+    }
+
+    return nil
+}
+
+// anySet reports whether any variable of the struct type t with the given
+// prefix is set. Variables which fail to resolve count as set, so that
+// their error is reported.
+func (e *Env) anySet(t reflect.Type, prefix string) bool {
+    for _, v := range e.describeStruct(t, prefix) {
+        if _, err := e.lookup(v.Name); !errors.Is(err, ErrNotSet) {
+            return true
+        }
+    }
+
+    return false
+}
+
+// skipField reports whether field is not bound to the environment because it
+// is unexported or tagged with `env:"-"`. Embedded structs of unexported
+// types are bound, as their exported fields are promoted.
+func skipField(field reflect.StructField) bool {
+    if field.PkgPath != "" && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+        return true
+    }
+
+    return field.Tag.Get("env") == "-"
+}
+
+// nestedPrefix reports whether field is a nested struct or a pointer to one
+// and returns the prefix of its fields. Embedded structs and fields tagged
+// with `flatten:"true"` keep the prefix of their parent, the "env" tag
+// replaces the name of the field as sub-prefix.
+func nestedPrefix(field reflect.StructField, prefix string) (string, bool) {
+    if _, ok := nestedType(field.Type); !ok {
+        return "", false
+    }
+
+    if tagBool(field.Tag, "flatten") || (field.Anonymous && field.Tag.Get("env") == "") {
+        return prefix, true
+    }
+
+    return joinPrefix(prefix, fieldName(field)), true
+}
+
+// nestedType returns the struct type of the nested struct t or of the
+// nested struct t points to. Types with a converter are not nested.
+func nestedType(t reflect.Type) (reflect.Type, bool) {
+    if _, _, ok := lookupConverter(t); ok {
+        return nil, false
+    }
+    if t.Kind() == reflect.Ptr {
+        t = t.Elem()
+        if _, _, ok := lookupConverter(t); ok {
+            return nil, false
+        }
+    }
+
+    return t, t.Kind() == reflect.Struct
+}
+
+// fieldName returns the name of the variable bound to field, the "env" tag
+// or the field name split at word boundaries.
+func fieldName(field reflect.StructField) string {
+    if name := field.Tag.Get("env"); name != "" {
+        return name
+    }
+
+    return splitWords(field.Name)
+}
+
+// joinPrefix appends name to prefix, separated by a space which becomes an
+// underscore in the key.
+func joinPrefix(prefix, name string) string {
+    if prefix == "" {
+        return name
+    }
+
+    return prefix + " " + name
+}
+
+// fieldVariable describes the variable bound to field of a struct whose
+// variables are prefixed with prefix. It returns false if the field is
+// tagged with `env:"-"`.
+func (e *Env) fieldVariable(field reflect.StructField, prefix string) (*Variable, bool) {
+    if field.Tag.Get("env") == "-" {
+        return nil, false
+    }
+    name := joinPrefix(prefix, fieldName(field))
+
+    return &Variable{
+        Name:     name,
+        Key:      e.prepareKey(name),
+        Type:     field.Type.String(),
+        Default:  field.Tag.Get("default"),
+        Usage:    field.Tag.Get("usage"),
+        Required: tagBool(field.Tag, "required"),
+        Secret:   field.Type == typeOf[Secret]() || tagBool(field.Tag, "secret"),
+    }, true
+}
+
+// tagBool reports whether the tag with the given name is set to a true value.
+func tagBool(tag reflect.StructTag, name string) bool {
+    str := tag.Get(name)
+    if str == "" {
+        return false
+    }
+
+    v, ok := parseBool(str)
+    return v && ok
+}
+
+// splitWords converts a Go identifier into space separated words, so that
+// prepareKey turns ReadTimeout into READ_TIMEOUT and HTTPPort into HTTP_PORT.
+func splitWords(name string) string {
+    runes := []rune(name)
+    words := make([]rune, 0, len(runes)+4)
+
+    for i, r := range runes {
+        if i > 0 && unicode.IsUpper(r) {
+            prev := runes[i-1]
+            nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+            if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+                words = append(words, ' ')
+            }
+        }
+        words = append(words, r)
+    }
+
+    return string(words)
+}
+
+// wordSeparators turns the separators of flag names and other keys into
+// word boundaries.
+var wordSeparators = strings.NewReplacer("-", " ", ".", " ", "_", " ")
+
+// keyWords splits name into the words of a key, e.g. "max conns" for
+// maxConns or max-conns.
+func keyWords(name string) string {
+    return splitWords(wordSeparators.Replace(name))
+}
@@ -0,0 +1,251 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "net/url"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type bindTestConfig struct {
+    Host        string        `env:"envconf_bind_host" default:"localhost"`
+    Port        int           `env:"envconf_bind_port" default:"8080"`
+    Debug       bool          `env:"envconf_bind_debug"`
+    MaxSize     int64         `env:"envconf_bind_max_size"`
+    Workers     uint          `env:"envconf_bind_workers" default:"4"`
+    Limit       uint64        `env:"envconf_bind_limit"`
+    Ratio       float64       `env:"envconf_bind_ratio" default:"0.5"`
+    ReadTimeout time.Duration `env:"envconf_bind_read_timeout" default:"5s"`
+    Ignored     string        `env:"-"`
+    unexported  string
+}
+
+func TestSplitWords(t *testing.T) {
+    assert := assert.New(t)
+
+    assert.Equal("Port", splitWords("Port"))
+    assert.Equal("Read Timeout", splitWords("ReadTimeout"))
+    assert.Equal("HTTP Port", splitWords("HTTPPort"))
+    assert.Equal("DB Host", splitWords("DBHost"))
+    assert.Equal("ID", splitWords("ID"))
+    assert.Equal("Port2 Value", splitWords("Port2Value"))
+
+    assert.Equal("READ_TIMEOUT", prepareKey(splitWords("ReadTimeout")))
+}
+
+func TestProcess(t *testing.T) {
+    assert := assert.New(t)
+
+    for _, k := range []string{"envconf_bind_host", "envconf_bind_port", "envconf_bind_debug",
+        "envconf_bind_max_size", "envconf_bind_workers", "envconf_bind_limit",
+        "envconf_bind_ratio", "envconf_bind_read_timeout"} {
+        UnsetKey(k)
+    }
+
+    var cfg bindTestConfig
+    cfg.Ignored = "keep"
+    assert.NoError(Process(&cfg))
+    assert.Equal("localhost", cfg.Host)
+    assert.Equal(8080, cfg.Port)
+    assert.False(cfg.Debug)
+    assert.Equal(int64(0), cfg.MaxSize)
+    assert.Equal(uint(4), cfg.Workers)
+    assert.Equal(uint64(0), cfg.Limit)
+    assert.True(floatEquals(0.5, cfg.Ratio))
+    assert.Equal(5*time.Second, cfg.ReadTimeout)
+    assert.Equal("keep", cfg.Ignored)
+
+    SetString("envconf_bind_host", "example.com")
+    SetInt("envconf_bind_port", 443)
+    SetString("envconf_bind_debug", "yes")
+    SetInt64("envconf_bind_max_size", 1<<40)
+    SetUInt64("envconf_bind_limit", 9223372036854775809)
+    SetDuration("envconf_bind_read_timeout", 3*time.Minute)
+
+    assert.NoError(Process(&cfg))
+    assert.Equal("example.com", cfg.Host)
+    assert.Equal(443, cfg.Port)
+    assert.True(cfg.Debug)
+    assert.Equal(int64(1<<40), cfg.MaxSize)
+    assert.Equal(uint64(9223372036854775809), cfg.Limit)
+    assert.Equal(3*time.Minute, cfg.ReadTimeout)
+
+    SetString("envconf_bind_port", "blahBlah")
+    assert.Error(Process(&cfg))
+    UnsetKey("envconf_bind_port")
+
+    for _, k := range []string{"envconf_bind_host", "envconf_bind_debug",
+        "envconf_bind_max_size", "envconf_bind_limit", "envconf_bind_read_timeout"} {
+        UnsetKey(k)
+    }
+}
+
+func TestProcessKeys(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        ListenAddress string
+        Token         string `required:"true"`
+    }
+
+    SetPrefix("envconf_bind")
+    defer SetPrefix("")
+
+    UnsetKey("token")
+    SetString("listen address", ":80")
+    assert.Error(Process(&cfg))
+
+    SetString("token", "secret")
+    assert.NoError(Process(&cfg))
+    assert.Equal(":80", cfg.ListenAddress)
+    assert.Equal("secret", cfg.Token)
+    assert.True(IssetKey("listen_address"))
+
+    UnsetKey("listen address")
+    UnsetKey("token")
+}
+
+func TestProcessInvalid(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg bindTestConfig
+    assert.Error(Process(cfg))
+    assert.Error(Process(nil))
+    assert.Error(Process((*bindTestConfig)(nil)))
+
+    n := 42
+    assert.Error(Process(&n))
+
+    var unsupported struct {
+        Value complex128 `env:"envconf_bind_value" default:"1+2i"`
+    }
+    assert.Error(Process(&unsupported))
+}
+
+type bindTestDatabase struct {
+    Host string `default:"localhost"`
+    Port int    `default:"5432" max:"65535"`
+}
+
+func (d *bindTestDatabase) Validate() error {
+    if d.Host == "invalid" {
+        return errors.New("invalid host")
+    }
+
+    return nil
+}
+
+type bindTestCommon struct {
+    LogLevel string `default:"info"`
+}
+
+type bindTestNested struct {
+    bindTestCommon
+    Primary bindTestDatabase `env:"primary db"`
+    Replica bindTestDatabase `env:"replica db"`
+    Cache   struct {
+        TTL time.Duration `default:"1m"`
+    }
+    Flat    bindTestDatabase `flatten:"true"`
+    Skipped bindTestDatabase `env:"-"`
+    URL     *url.URL
+}
+
+func TestProcessNested(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_LOG_LEVEL":       "debug",
+        "APP_PRIMARY_DB_HOST": "primary",
+        "APP_REPLICA_DB_HOST": "replica",
+        "APP_REPLICA_DB_PORT": "5433",
+        "APP_CACHE_TTL":       "5m",
+        "APP_HOST":            "flat",
+        "APP_URL":             "https://example.com",
+    }))
+
+    var c bindTestNested
+    assert.NoError(e.Process(&c))
+    assert.Equal("debug", c.LogLevel)
+    assert.Equal(bindTestDatabase{Host: "primary", Port: 5432}, c.Primary)
+    assert.Equal(bindTestDatabase{Host: "replica", Port: 5433}, c.Replica)
+    assert.Equal(5*time.Minute, c.Cache.TTL)
+    assert.Equal(bindTestDatabase{Host: "flat", Port: 5432}, c.Flat)
+    assert.Equal(bindTestDatabase{}, c.Skipped)
+    assert.Equal("example.com", c.URL.Host)
+
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PRIMARY_DB_HOST": "invalid",
+        "APP_REPLICA_DB_PORT": "99999",
+    }))
+    err := e.Process(&c)
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 2)
+    assert.Equal("APP_PRIMARY_DB", verr.Problems[0].Key)
+    assert.EqualError(verr.Problems[0].Err, "invalid host")
+    assert.Equal("APP_REPLICA_DB_PORT", verr.Problems[1].Key)
+}
+
+type bindTestNode struct {
+    Value string
+    Next  *bindTestNode
+}
+
+type bindTestOptional struct {
+    DB    *bindTestDatabase
+    Cache *bindTestDatabase
+    List  *bindTestNode
+    URL   *url.URL
+}
+
+func TestProcessNestedPointer(t *testing.T) {
+    assert := assert.New(t)
+
+    src := NewMapSource(map[string]string{
+        "APP_DB_HOST":         "db",
+        "APP_LIST_VALUE":      "a",
+        "APP_LIST_NEXT_VALUE": "b",
+    })
+    e := New("app")
+    e.SetSource(src)
+
+    var c bindTestOptional
+    assert.NoError(e.Process(&c))
+    assert.Equal(&bindTestDatabase{Host: "db", Port: 5432}, c.DB)
+    assert.Nil(c.Cache)
+    if assert.NotNil(c.List) && assert.NotNil(c.List.Next) {
+        assert.Equal("a", c.List.Value)
+        assert.Equal("b", c.List.Next.Value)
+        assert.Nil(c.List.Next.Next)
+    }
+    assert.Nil(c.URL)
+
+    vars, err := e.Describe(&c)
+    assert.NoError(err)
+    var keys []string
+    for _, v := range vars {
+        keys = append(keys, v.Key)
+    }
+    assert.Equal([]string{"APP_DB_HOST", "APP_DB_PORT", "APP_CACHE_HOST", "APP_CACHE_PORT", "APP_LIST_VALUE", "APP_URL"}, keys)
+
+    e.SetSource(NewMapSource(nil))
+    assert.NoError(e.Marshal(&c))
+    assert.Equal("b", e.MustGetString("list next value"))
+    assert.False(e.IssetKey("cache host"))
+
+    e.SetSource(NewMapSource(map[string]string{"APP_DB_HOST": "invalid", "APP_CACHE_PORT": "99999"}))
+    var d bindTestOptional
+    var verr *ValidationError
+    assert.True(errors.As(e.Process(&d), &verr))
+    assert.Len(verr.Problems, 2)
+    assert.Nil(d.DB)
+    assert.Nil(d.Cache)
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "reflect"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// IndexError is reported if the indices of a slice of structs are not
+// consecutive, e.g. if APP_SERVERS_0_HOST and APP_SERVERS_2_HOST are set
+// but no variable of index 1.
+type IndexError struct {
+    Key   string // normalized and prefixed key of the slice, e.g. APP_SERVERS
+    Index int    // first missing index
+}
+
+func (e *IndexError) Error() string {
+    return "Environment variables of index " + strconv.Itoa(e.Index) + " of \"" + e.Key + "\" not found"
+}
+
+// isCollection reports whether t is a slice of structs or a map from string
+// to structs, which are bound to indexed keys.
+func isCollection(t reflect.Type) bool {
+    switch {
+    case t.Kind() == reflect.Slice:
+    case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+    default:
+        return false
+    }
+
+    if t.Elem().Kind() != reflect.Struct {
+        return false
+    }
+    _, _, ok := lookupConverter(t.Elem())
+    return !ok
+}
+
+// Placeholders for the index or name of elements of collections shown in
+// usage output.
+const (
+    indexPlaceholder = "<n>"
+    namePlaceholder  = "<name>"
+)
+
+// collectionPlaceholder returns the placeholder for the index or name of
+// elements of the collection type t.
+func collectionPlaceholder(t reflect.Type) string {
+    if t.Kind() == reflect.Slice {
+        return indexPlaceholder
+    }
+
+    return namePlaceholder
+}
+
+// processCollection populates the slice or map of structs fv from all keys
+// of the Source starting with prefix. The elements of a slice are read from
+// PREFIX_0_*, PREFIX_1_* and so on, the elements of a map from
+// PREFIX_<NAME>_*. The collection is left untouched if no key is found.
+func (e *Env) processCollection(fv reflect.Value, prefix string) []Problem {
+    t := fv.Type()
+    problem := Problem{Key: e.prepareKey(prefix), Type: t.String()}
+
+    names, err := e.collectionNames(prefix, t.Elem())
+    if err != nil {
+        problem.Err = err
+        return []Problem{problem}
+    }
+    if len(names) == 0 {
+        return nil
+    }
+
+    var problems []Problem
+    if t.Kind() == reflect.Slice {
+        indices := make(map[int]bool)
+        last := -1
+        for _, name := range names {
+            i, err := strconv.Atoi(name)
+            if err != nil || i < 0 || strconv.Itoa(i) != name {
+                continue
+            }
+            indices[i] = true
+            if i > last {
+                last = i
+            }
+        }
+
+        for i := 0; i <= last; i++ {
+            if !indices[i] {
+                problem.Err = &IndexError{Key: problem.Key, Index: i}
+                return []Problem{problem}
+            }
+        }
+
+        slice := reflect.MakeSlice(t, last+1, last+1)
+        for i := 0; i <= last; i++ {
+            problems = append(problems, e.processNested(slice.Index(i), joinPrefix(prefix, strconv.Itoa(i)))...)
+        }
+        if len(problems) == 0 && last >= 0 {
+            fv.Set(slice)
+        }
+
+        return problems
+    }
+
+    m := reflect.MakeMapWithSize(t, len(names))
+    for _, name := range names {
+        elem := reflect.New(t.Elem()).Elem()
+        problems = append(problems, e.processNested(elem, joinPrefix(prefix, name))...)
+        m.SetMapIndex(reflect.ValueOf(name).Convert(t.Key()), elem)
+    }
+    if len(problems) == 0 {
+        fv.Set(m)
+    }
+
+    return problems
+}
+
+// collectionNames returns the sorted indices or names of all elements of a
+// collection of elem whose variables are set. A key belongs to an element
+// if it consists of the key of the collection, the name of the element and
+// the key of one of the fields of elem, e.g. APP_SERVERS, 0 and _HOST, or of
+// a collection nested in elem, e.g. _TARGETS_0_HOST. The Source must
+// implement Lister.
+func (e *Env) collectionNames(prefix string, elem reflect.Type) ([]string, error) {
+    lister, ok := e.source.(Lister)
+    if !ok {
+        return nil, errors.New("Source does not support listing environment variables")
+    }
+
+    // Describe the element with a placeholder as name to determine the
+    // common head and the suffixes of all keys.
+    const placeholder = "\x00"
+    var head string
+    var suffixes []string
+    for _, v := range e.describeStruct(elem, joinPrefix(prefix, placeholder)) {
+        i := strings.Index(v.Key, placeholder)
+        if i < 0 {
+            continue
+        }
+        head = v.Key[:i]
+        suffixes = append(suffixes, v.Key[i+len(placeholder):])
+        if e.fileFallback {
+            suffixes = append(suffixes, v.Key[i+len(placeholder):]+"_FILE")
+        }
+    }
+
+    // Collections nested in elem appear as placeholders in the suffixes,
+    // which match any index or name.
+    index := regexp.QuoteMeta(e.keyFunc(indexPlaceholder))
+    name := regexp.QuoteMeta(e.keyFunc(namePlaceholder))
+    patterns := make([]*regexp.Regexp, len(suffixes))
+    for i, suffix := range suffixes {
+        pattern := regexp.QuoteMeta(suffix)
+        pattern = strings.Replace(pattern, index, "[0-9]+", -1)
+        pattern = strings.Replace(pattern, name, ".+", -1)
+        patterns[i] = regexp.MustCompile("^" + regexp.QuoteMeta(head) + "(.+?)" + pattern + "$")
+    }
+
+    // A key belongs to the element determined by the longest matching
+    // suffix, so APP_UPS_EU_ADMIN_PORT belongs to EU, not to EU_ADMIN, if
+    // the element has the fields AdminPort and Port.
+    found := make(map[string]bool)
+    for _, key := range lister.Keys() {
+        if !strings.HasPrefix(key, head) {
+            continue
+        }
+
+        shortest := ""
+        for _, re := range patterns {
+            if m := re.FindStringSubmatch(key); m != nil && (shortest == "" || len(m[1]) < len(shortest)) {
+                shortest = m[1]
+            }
+        }
+        if shortest != "" {
+            found[shortest] = true
+        }
+    }
+
+    names := make([]string, 0, len(found))
+    for name := range found {
+        if !shadowedName(name, found, suffixes) {
+            names = append(names, name)
+        }
+    }
+    sort.Strings(names)
+
+    return names, nil
+}
+
+// shadowedName reports whether name consists of another found name and the
+// head of one of the suffixes, e.g. EU_ADMIN of EU and _ADMIN_PORT. Such a
+// name stems from a key of the other element.
+func shadowedName(name string, found map[string]bool, suffixes []string) bool {
+    for other := range found {
+        if len(other) >= len(name) || !strings.HasPrefix(name, other) {
+            continue
+        }
+
+        head := name[len(other):]
+        for _, suffix := range suffixes {
+            if strings.HasPrefix(suffix, head+"_") {
+                return true
+            }
+        }
+    }
+
+    return false
+}
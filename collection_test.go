@@ -0,0 +1,196 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type collectionTestUpstream struct {
+    Host    string `required:"true"`
+    Port    int    `default:"80"`
+    TLSCert string `env:"tls cert"`
+}
+
+type collectionTestConfig struct {
+    Upstreams []collectionTestUpstream
+    Regions   map[string]collectionTestUpstream
+    Tags      []string
+}
+
+func TestProcessCollections(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_UPSTREAMS_0_HOST":     "a.example.com",
+        "APP_UPSTREAMS_1_HOST":     "b.example.com",
+        "APP_UPSTREAMS_1_PORT":     "8080",
+        "APP_UPSTREAMS_10_HOST":    "k.example.com",
+        "APP_UPSTREAMS_2_HOST":     "c.example.com",
+        "APP_UPSTREAMS_3_HOST":     "d.example.com",
+        "APP_UPSTREAMS_4_HOST":     "e.example.com",
+        "APP_UPSTREAMS_5_HOST":     "f.example.com",
+        "APP_UPSTREAMS_6_HOST":     "g.example.com",
+        "APP_UPSTREAMS_7_HOST":     "h.example.com",
+        "APP_UPSTREAMS_8_HOST":     "i.example.com",
+        "APP_UPSTREAMS_9_TLS_CERT": "/etc/tls.crt",
+        "APP_UPSTREAMS_9_HOST":     "j.example.com",
+        "APP_UPSTREAMS_X_HOST":     "ignored",
+        "APP_REGIONS_EU_WEST_HOST": "eu.example.com",
+        "APP_REGIONS_US_HOST":      "us.example.com",
+        "APP_REGIONS_US_PORT":      "443",
+        "APP_TAGS":                 "a,b",
+    }))
+
+    var c collectionTestConfig
+    assert.NoError(e.Process(&c))
+    assert.Len(c.Upstreams, 11)
+    assert.Equal(collectionTestUpstream{Host: "a.example.com", Port: 80}, c.Upstreams[0])
+    assert.Equal(collectionTestUpstream{Host: "b.example.com", Port: 8080}, c.Upstreams[1])
+    assert.Equal(collectionTestUpstream{Host: "j.example.com", Port: 80, TLSCert: "/etc/tls.crt"}, c.Upstreams[9])
+    assert.Equal("k.example.com", c.Upstreams[10].Host)
+    assert.Equal(map[string]collectionTestUpstream{
+        "EU_WEST": {Host: "eu.example.com", Port: 80},
+        "US":      {Host: "us.example.com", Port: 443},
+    }, c.Regions)
+    assert.Equal([]string{"a", "b"}, c.Tags)
+
+    c = collectionTestConfig{}
+    e.SetSource(NewMapSource(nil))
+    assert.NoError(e.Process(&c))
+    assert.Nil(c.Upstreams)
+    assert.Nil(c.Regions)
+}
+
+func TestProcessCollectionErrors(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_UPSTREAMS_0_HOST": "a.example.com",
+        "APP_UPSTREAMS_2_HOST": "c.example.com",
+        "APP_REGIONS_EU_PORT":  "443",
+    }))
+
+    upstreams := []collectionTestUpstream{{Host: "default"}}
+    c := collectionTestConfig{Upstreams: upstreams}
+    err := e.Process(&c)
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 2)
+
+    var ierr *IndexError
+    assert.True(errors.As(verr.Problems[0].Err, &ierr))
+    assert.Equal(&IndexError{Key: "APP_UPSTREAMS", Index: 1}, ierr)
+    assert.EqualError(ierr, `Environment variables of index 1 of "APP_UPSTREAMS" not found`)
+    assert.Equal(upstreams, c.Upstreams)
+
+    assert.Equal("APP_REGIONS_EU_HOST", verr.Problems[1].Key)
+    assert.True(errors.Is(verr.Problems[1].Err, ErrNotSet))
+    assert.Nil(c.Regions)
+
+    e.SetSource(LookupFunc(func(string) (string, bool) { return "", false }))
+    assert.Error(e.Process(&c))
+}
+
+func TestProcessCollectionOverlappingFields(t *testing.T) {
+    assert := assert.New(t)
+
+    type server struct {
+        Port      int
+        AdminPort int
+    }
+    var c struct {
+        Ups     map[string]server
+        Servers []server
+    }
+
+    e := New("p")
+    e.SetSource(NewMapSource(map[string]string{
+        "P_UPS_EU_PORT":          "1",
+        "P_UPS_EU_ADMIN_PORT":    "2",
+        "P_UPS_US_ADMIN_PORT":    "3",
+        "P_SERVERS_0_PORT":       "4",
+        "P_SERVERS_0_ADMIN_PORT": "5",
+        "P_SERVERS_1_ADMIN_PORT": "6",
+    }))
+
+    assert.NoError(e.Process(&c))
+    assert.Equal(map[string]server{"EU": {Port: 1, AdminPort: 2}, "US": {AdminPort: 3}}, c.Ups)
+    assert.Equal([]server{{Port: 4, AdminPort: 5}, {AdminPort: 6}}, c.Servers)
+}
+
+func TestProcessNestedCollections(t *testing.T) {
+    assert := assert.New(t)
+
+    type item struct {
+        Name string
+        Subs []collectionTestUpstream
+    }
+    var c struct {
+        Items  []item
+        Groups map[string]item
+    }
+
+    e := New("p")
+    e.SetSource(NewMapSource(map[string]string{
+        "P_ITEMS_0_SUBS_0_HOST":            "a",
+        "P_ITEMS_0_SUBS_1_HOST":            "b",
+        "P_ITEMS_1_NAME":                   "second",
+        "P_ITEMS_1_SUBS_0_HOST":            "c",
+        "P_ITEMS_1_SUBS_0_PORT":            "8080",
+        "P_GROUPS_EU_WEST_SUBS_0_HOST":     "d",
+        "P_GROUPS_EU_WEST_SUBS_0_TLS_CERT": "/etc/tls.crt",
+    }))
+
+    assert.NoError(e.Process(&c))
+    assert.Equal([]item{
+        {Subs: []collectionTestUpstream{{Host: "a", Port: 80}, {Host: "b", Port: 80}}},
+        {Name: "second", Subs: []collectionTestUpstream{{Host: "c", Port: 8080}}},
+    }, c.Items)
+    assert.Equal(map[string]item{
+        "EU_WEST": {Subs: []collectionTestUpstream{{Host: "d", Port: 80, TLSCert: "/etc/tls.crt"}}},
+    }, c.Groups)
+
+    e.SetString("items 0 subs 3 host", "x")
+    var perr *IndexError
+    assert.True(errors.As(e.Process(&c), &perr))
+}
+
+func TestProcessCollectionFileFallback(t *testing.T) {
+    assert := assert.New(t)
+
+    path := writeTestFile(t, "cert", "/etc/tls.crt\n")
+    e := New("app")
+    e.SetFileFallback(true)
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_UPSTREAMS_0_HOST": "a.example.com",
+        "APP_UPSTREAMS_1_HOST": "b.example.com",
+        "APP_UPSTREAMS_1_TLS_CERT_FILE": path,
+    }))
+
+    var c collectionTestConfig
+    assert.NoError(e.Process(&c))
+    assert.Len(c.Upstreams, 2)
+    assert.Equal("/etc/tls.crt", c.Upstreams[1].TLSCert)
+}
+
+func TestDescribeCollections(t *testing.T) {
+    assert := assert.New(t)
+
+    vars, err := New("app").Describe(collectionTestConfig{})
+    assert.NoError(err)
+
+    var keys []string
+    for _, v := range vars {
+        keys = append(keys, v.Key)
+    }
+    assert.Equal([]string{"APP_UPSTREAMS_<N>_HOST", "APP_UPSTREAMS_<N>_PORT", "APP_UPSTREAMS_<N>_TLS_CERT",
+        "APP_REGIONS_<NAME>_HOST", "APP_REGIONS_<NAME>_PORT", "APP_REGIONS_<NAME>_TLS_CERT", "APP_TAGS"}, keys)
+}
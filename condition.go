@@ -0,0 +1,137 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "reflect"
+    "strings"
+)
+
+// specValidator is implemented by structs passed to Process which validate
+// themselves after all fields are populated.
+type specValidator interface {
+    Validate() error
+}
+
+// boundField is the state of a struct field during Process.
+type boundField struct {
+    field   reflect.StructField
+    value   reflect.Value
+    str     string  // raw value or default
+    present bool    // set in the environment
+    valued  bool    // set in the environment or by a default
+    failed  bool    // already reported as problem
+    problem Problem // template for problems of the field
+}
+
+func (bf *boundField) problemWith(err error) Problem {
+    p := bf.problem
+    p.Err = err
+    return p
+}
+
+// conditionError describes a variable which is missing although a condition
+// requires it. It matches ErrNotSet.
+type conditionError struct {
+    msg string
+}
+
+func (e *conditionError) Error() string {
+    return e.msg
+}
+
+func (e *conditionError) Is(target error) bool {
+    return target == ErrNotSet
+}
+
+// checkConditions checks the tags required_if, required_unless and
+// excluded_with of all fields which did not fail yet.
+func (e *Env) checkConditions(bound []*boundField, fields map[string]*boundField) []Problem {
+    var problems []Problem
+    for _, bf := range bound {
+        if bf.failed {
+            continue
+        }
+
+        if err := e.checkCondition(bf, fields); err != nil {
+            problems = append(problems, bf.problemWith(err))
+        }
+    }
+
+    return problems
+}
+
+func (e *Env) checkCondition(bf *boundField, fields map[string]*boundField) error {
+    key := bf.problem.Key
+    ruleError := func(name, tag string, err error) error {
+        value := ""
+        if bf.valued {
+            value = e.redact(key, bf.str)
+        }
+
+        return &RuleError{Key: key, Value: value, Rule: name + "=" + tag, Err: err}
+    }
+
+    for _, name := range []string{"required_if", "required_unless"} {
+        tag, ok := bf.field.Tag.Lookup(name)
+        if !ok || bf.valued {
+            continue
+        }
+
+        match, desc, err := e.matchFields(tag, fields)
+        if err != nil {
+            return ruleError(name, tag, err)
+        }
+        if name == "required_if" && match {
+            return ruleError(name, tag, &conditionError{"required if " + desc})
+        }
+        if name == "required_unless" && !match {
+            return ruleError(name, tag, &conditionError{"required unless " + desc})
+        }
+    }
+
+    if tag, ok := bf.field.Tag.Lookup("excluded_with"); ok && bf.present {
+        for _, name := range strings.Fields(tag) {
+            other, ok := fields[name]
+            if !ok {
+                return ruleError("excluded_with", tag, errors.New("unknown field "+name))
+            }
+            if other.present {
+                return ruleError("excluded_with", tag, errors.New("must not be set together with "+other.problem.Key))
+            }
+        }
+    }
+
+    return nil
+}
+
+// matchFields reports whether all pairs of field name and value in tag match
+// the current values of fields. It also returns a description of the pairs.
+func (e *Env) matchFields(tag string, fields map[string]*boundField) (bool, string, error) {
+    pairs := strings.Fields(tag)
+    if len(pairs) == 0 || len(pairs)%2 != 0 {
+        return false, "", errors.New("requires pairs of field name and value")
+    }
+
+    match := true
+    var desc []string
+    for i := 0; i < len(pairs); i += 2 {
+        other, ok := fields[pairs[i]]
+        if !ok {
+            return false, "", errors.New("unknown field " + pairs[i])
+        }
+
+        want, err := e.parseValue(other.field.Type, pairs[i+1])
+        if err != nil {
+            return false, "", errors.New("invalid value " + pairs[i+1] + " for field " + pairs[i])
+        }
+
+        match = match && reflect.DeepEqual(other.value.Interface(), want.Interface())
+        desc = append(desc, other.problem.Key+" is "+pairs[i+1])
+    }
+
+    return match, strings.Join(desc, " and "), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type conditionTestConfig struct {
+    TLSEnabled   bool          `env:"tls enabled"`
+    TLSCert      string        `env:"tls cert" required_if:"TLSEnabled true"`
+    Mode         string        `default:"prod"`
+    DebugAddr    string        `required_unless:"Mode prod"`
+    Password     string        `excluded_with:"PasswordFile"`
+    PasswordFile string        `env:"password file"`
+    CacheTTL     time.Duration `env:"cache ttl" default:"1m"`
+    SessionTTL   time.Duration `env:"session ttl" default:"1h"`
+}
+
+func (c *conditionTestConfig) Validate() error {
+    if c.CacheTTL >= c.SessionTTL {
+        return errors.New("CACHE_TTL must be less than SESSION_TTL")
+    }
+
+    return nil
+}
+
+func TestProcessConditions(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_PASSWORD": "hunter2"}))
+
+    var c conditionTestConfig
+    assert.NoError(e.Process(&c))
+
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_TLS_ENABLED":   "true",
+        "APP_TLS_CERT":      "/etc/tls.crt",
+        "APP_MODE":          "dev",
+        "APP_DEBUG_ADDR":    ":6060",
+        "APP_PASSWORD_FILE": "/run/secrets/password",
+    }))
+    assert.NoError(e.Process(&c))
+    assert.Equal("/etc/tls.crt", c.TLSCert)
+
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_TLS_ENABLED":   "yes",
+        "APP_MODE":          "dev",
+        "APP_PASSWORD":      "hunter2",
+        "APP_PASSWORD_FILE": "/run/secrets/password",
+    }))
+    err := e.Process(&c)
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 3)
+
+    assert.Equal("APP_TLS_CERT", verr.Problems[0].Key)
+    assert.True(errors.Is(verr.Problems[0].Err, ErrNotSet))
+    assert.Equal("required if APP_TLS_ENABLED is true", verr.Problems[0].Description())
+    assert.EqualError(verr.Problems[0].Err,
+        `Environment variable "APP_TLS_CERT" violates rule required_if=TLSEnabled true: required if APP_TLS_ENABLED is true`)
+
+    assert.Equal("APP_DEBUG_ADDR", verr.Problems[1].Key)
+    assert.Equal("required unless APP_MODE is prod", verr.Problems[1].Description())
+
+    assert.Equal("APP_PASSWORD", verr.Problems[2].Key)
+    assert.False(errors.Is(verr.Problems[2].Err, ErrNotSet))
+    assert.Equal(`invalid value "[REDACTED]": must not be set together with APP_PASSWORD_FILE`, verr.Problems[2].Description())
+}
+
+func TestProcessConditionErrors(t *testing.T) {
+    assert := assert.New(t)
+
+    type config struct {
+        A string `required_if:"Missing true"`
+        B string `required_unless:"A"`
+        C string `excluded_with:"Missing"`
+        D int
+        E string `required_if:"D x"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_C": "c"}))
+
+    err := e.Process(&config{})
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 4)
+    assert.Equal("invalid value \"\": unknown field Missing", verr.Problems[0].Description())
+    assert.Equal("invalid value \"\": requires pairs of field name and value", verr.Problems[1].Description())
+    assert.Equal("invalid value \"c\": unknown field Missing", verr.Problems[2].Description())
+    assert.Equal("invalid value \"\": invalid value x for field D", verr.Problems[3].Description())
+}
+
+func TestProcessValidate(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_CACHE_TTL": "2h"}))
+
+    var c conditionTestConfig
+    assert.EqualError(e.Process(&c), "CACHE_TTL must be less than SESSION_TTL")
+    assert.Equal(2*time.Hour, c.CacheTTL)
+
+    e.SetSource(NewMapSource(map[string]string{"APP_CACHE_TTL": "2h", "APP_SESSION_TTL": "x"}))
+    var verr *ValidationError
+    assert.True(errors.As(e.Process(&c), &verr))
+}
@@ -0,0 +1,913 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "encoding"
+    "flag"
+    "io"
+    "time"
+)
+
+// std is the Env used by the package level functions.
+var std = New("")
+
+// DefaultEnv returns the Env used by the package level functions.
+func DefaultEnv() *Env {
+    return std
+}
+
+func prepareKey(key string) string {
+    return std.prepareKey(key)
+}
+
+// GetPrefix returns the prefix that is automatically prepended to a environment variable.
+func GetPrefix() string {
+    return std.GetPrefix()
+}
+
+// SetPrefix sets the prefix which is automatically prepended to an environment variable.
+func SetPrefix(p string) {
+    std.SetPrefix(p)
+}
+
+// GetSource returns the Source values are looked up from.
+func GetSource() Source {
+    return std.GetSource()
+}
+
+// SetSource sets the Source values are looked up from. A nil Source restores
+// the process environment.
+func SetSource(s Source) {
+    std.SetSource(s)
+}
+
+// UnsetKey unsets a single environment variable.
+func UnsetKey(key string) {
+    std.UnsetKey(key)
+}
+
+// IssetKey determine if a environment variable is set.
+func IssetKey(key string) bool {
+    return std.IssetKey(key)
+}
+
+// SetDefaultString sets the environment if it is not already set.
+func SetDefaultString(key string, value string) {
+    std.SetDefaultString(key, value)
+}
+
+// SetString sets the environment.
+func SetString(key string, value string) {
+    std.SetString(key, value)
+}
+
+// LookupString returns the environment parsed as string. The error matches
+// ErrNotSet if the environment does not exist.
+func LookupString(key string) (value string, err error) {
+    return std.LookupString(key)
+}
+
+// GetString returns the environment parsed as string.
+// Returns an empty string if the environment does not exists or could not be
+// parsed.
+func GetString(key string) (value string, ok bool) {
+    return std.GetString(key)
+}
+
+// MustGetString returns the environment variable parsed as string
+// if possible, otherwise it panics.
+func MustGetString(key string) (value string) {
+    return std.MustGetString(key)
+}
+
+// SetDefaultBool sets the environment if it is not already set.
+func SetDefaultBool(key string, value bool) {
+    std.SetDefaultBool(key, value)
+}
+
+// SetBool sets the environment.
+func SetBool(key string, value bool) {
+    std.SetBool(key, value)
+}
+
+// LookupBool returns the environment parsed as bool.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupBool(key string) (value bool, err error) {
+    return std.LookupBool(key)
+}
+
+// GetBool returns the environment parsed as bool. A not existing environment
+// variable is considered to be false, an empty one to be true.
+func GetBool(key string) (value bool, ok bool) {
+    return std.GetBool(key)
+}
+
+// MustGetBool returns the environment variable parsed as bool
+// if possible, otherwise it panics.
+func MustGetBool(key string) (value bool) {
+    return std.MustGetBool(key)
+}
+
+// SetDefaultDuration sets the environment if it is not already set.
+func SetDefaultDuration(key string, value time.Duration) {
+    std.SetDefaultDuration(key, value)
+}
+
+// SetDuration sets the environment.
+func SetDuration(key string, value time.Duration) {
+    std.SetDuration(key, value)
+}
+
+// LookupDuration returns the environment parsed as time.Duration.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupDuration(key string) (value time.Duration, err error) {
+    return std.LookupDuration(key)
+}
+
+// GetDuration returns the environment parsed as time.Duration.
+func GetDuration(key string) (value time.Duration, ok bool) {
+    return std.GetDuration(key)
+}
+
+// MustGetDuration returns the environment variable parsed as time.Duration
+// if possible, otherwise it panics.
+func MustGetDuration(key string) time.Duration {
+    return std.MustGetDuration(key)
+}
+
+// SetDefaultFloat64 sets the environment if it is not already set.
+func SetDefaultFloat64(key string, value float64) {
+    std.SetDefaultFloat64(key, value)
+}
+
+// SetFloat64 sets the environment.
+func SetFloat64(key string, value float64) {
+    std.SetFloat64(key, value)
+}
+
+// LookupFloat64 returns the environment parsed as float64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupFloat64(key string) (value float64, err error) {
+    return std.LookupFloat64(key)
+}
+
+// GetFloat64 returns the environment parsed as float64.
+func GetFloat64(key string) (value float64, ok bool) {
+    return std.GetFloat64(key)
+}
+
+// MustGetFloat64 returns the environment variable parsed as float64
+// if possible, otherwise it panics.
+func MustGetFloat64(key string) float64 {
+    return std.MustGetFloat64(key)
+}
+
+// SetDefaultInt sets the environment if it is not already set.
+func SetDefaultInt(key string, value int) {
+    std.SetDefaultInt(key, value)
+}
+
+// SetInt sets the environment.
+func SetInt(key string, value int) {
+    std.SetInt(key, value)
+}
+
+// LookupInt returns the environment parsed as int.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupInt(key string) (value int, err error) {
+    return std.LookupInt(key)
+}
+
+// GetInt returns the environment parsed as int.
+func GetInt(key string) (value int, ok bool) {
+    return std.GetInt(key)
+}
+
+// MustGetInt returns the environment variable parsed as int
+// if possible, otherwise it panics.
+func MustGetInt(key string) (value int) {
+    return std.MustGetInt(key)
+}
+
+// SetDefaultInt64 sets the environment if it is not already set.
+func SetDefaultInt64(key string, value int64) {
+    std.SetDefaultInt64(key, value)
+}
+
+// SetInt64 sets the environment.
+func SetInt64(key string, value int64) {
+    std.SetInt64(key, value)
+}
+
+// LookupInt64 returns the environment parsed as int64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupInt64(key string) (value int64, err error) {
+    return std.LookupInt64(key)
+}
+
+// GetInt64 returns the environment parsed as int64.
+func GetInt64(key string) (value int64, ok bool) {
+    return std.GetInt64(key)
+}
+
+// MustGetInt64 returns the environment variable parsed as int64
+// if possible, otherwise it panics.
+func MustGetInt64(key string) (value int64) {
+    return std.MustGetInt64(key)
+}
+
+// SetDefaultUInt sets the environment if it is not already set.
+func SetDefaultUInt(key string, value uint) {
+    std.SetDefaultUInt(key, value)
+}
+
+// SetUInt sets the environment.
+func SetUInt(key string, value uint) {
+    std.SetUInt(key, value)
+}
+
+// LookupUInt returns the environment parsed as uint.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupUInt(key string) (value uint, err error) {
+    return std.LookupUInt(key)
+}
+
+// GetUInt returns the environment parsed as uint.
+func GetUInt(key string) (value uint, ok bool) {
+    return std.GetUInt(key)
+}
+
+// MustGetUInt returns the environment variable parsed as uint
+// if possible, otherwise it panics.
+func MustGetUInt(key string) (value uint) {
+    return std.MustGetUInt(key)
+}
+
+// SetDefaultUInt64 sets the environment if it is not already set.
+func SetDefaultUInt64(key string, value uint64) {
+    std.SetDefaultUInt64(key, value)
+}
+
+// SetUInt64 sets the environment.
+func SetUInt64(key string, value uint64) {
+    std.SetUInt64(key, value)
+}
+
+// LookupUInt64 returns the environment parsed as uint64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupUInt64(key string) (value uint64, err error) {
+    return std.LookupUInt64(key)
+}
+
+// GetUInt64 returns the environment parsed as uint64.
+func GetUInt64(key string) (value uint64, ok bool) {
+    return std.GetUInt64(key)
+}
+
+// MustGetUInt64 returns the environment variable parsed as uint64
+// if possible, otherwise it panics.
+func MustGetUInt64(key string) (value uint64) {
+    return std.MustGetUInt64(key)
+}
+
+// Process populates the struct pointed to by spec from the environment.
+// See Env.Process for details.
+func Process(spec interface{}) error {
+    return std.Process(spec)
+}
+
+// MustProcess calls Process. On failure it writes the report to standard
+// error and exits the program with status 1.
+func MustProcess(spec interface{}) {
+    std.MustProcess(spec)
+}
+
+// NewValidator returns a Validator which checks variables of the default Env.
+func NewValidator() *Validator {
+    return std.NewValidator()
+}
+
+// LoadFile reads a .env file into the default Env. See Env.LoadReader for
+// details.
+func LoadFile(path string, opts ...LoadOption) error {
+    return std.LoadFile(path, opts...)
+}
+
+// LoadReader reads variables in .env syntax from r into the default Env.
+// See Env.LoadReader for details.
+func LoadReader(r io.Reader, name string, opts ...LoadOption) error {
+    return std.LoadReader(r, name, opts...)
+}
+
+// SetExpand enables or disables the expansion of references in values of
+// the default Env.
+func SetExpand(enabled bool) {
+    std.SetExpand(enabled)
+}
+
+// Expand replaces references to environment variables in s.
+// See Env.Expand for the supported syntax.
+func Expand(s string) (string, error) {
+    return std.Expand(s)
+}
+
+// GetListOptions returns the options used by the default Env to split and
+// join lists.
+func GetListOptions() ListOptions {
+    return std.GetListOptions()
+}
+
+// SetListOptions sets the options used by the default Env to split and join
+// lists.
+func SetListOptions(o ListOptions) {
+    std.SetListOptions(o)
+}
+
+// SetDefaultStringSlice sets the environment if it is not already set.
+func SetDefaultStringSlice(key string, values []string) {
+    std.SetDefaultStringSlice(key, values)
+}
+
+// SetStringSlice sets the environment to the joined list of values.
+func SetStringSlice(key string, values []string) {
+    std.SetStringSlice(key, values)
+}
+
+// LookupStringSlice returns the environment parsed as list of string.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupStringSlice(key string) (values []string, err error) {
+    return std.LookupStringSlice(key)
+}
+
+// GetStringSlice returns the environment parsed as list of string.
+func GetStringSlice(key string) (values []string, ok bool) {
+    return std.GetStringSlice(key)
+}
+
+// MustGetStringSlice returns the environment variable parsed as list of
+// string if possible, otherwise it panics.
+func MustGetStringSlice(key string) (values []string) {
+    return std.MustGetStringSlice(key)
+}
+
+// SetDefaultBoolSlice sets the environment if it is not already set.
+func SetDefaultBoolSlice(key string, values []bool) {
+    std.SetDefaultBoolSlice(key, values)
+}
+
+// SetBoolSlice sets the environment to the joined list of values.
+func SetBoolSlice(key string, values []bool) {
+    std.SetBoolSlice(key, values)
+}
+
+// LookupBoolSlice returns the environment parsed as list of bool.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupBoolSlice(key string) (values []bool, err error) {
+    return std.LookupBoolSlice(key)
+}
+
+// GetBoolSlice returns the environment parsed as list of bool.
+func GetBoolSlice(key string) (values []bool, ok bool) {
+    return std.GetBoolSlice(key)
+}
+
+// MustGetBoolSlice returns the environment variable parsed as list of
+// bool if possible, otherwise it panics.
+func MustGetBoolSlice(key string) (values []bool) {
+    return std.MustGetBoolSlice(key)
+}
+
+// SetDefaultDurationSlice sets the environment if it is not already set.
+func SetDefaultDurationSlice(key string, values []time.Duration) {
+    std.SetDefaultDurationSlice(key, values)
+}
+
+// SetDurationSlice sets the environment to the joined list of values.
+func SetDurationSlice(key string, values []time.Duration) {
+    std.SetDurationSlice(key, values)
+}
+
+// LookupDurationSlice returns the environment parsed as list of time.Duration.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupDurationSlice(key string) (values []time.Duration, err error) {
+    return std.LookupDurationSlice(key)
+}
+
+// GetDurationSlice returns the environment parsed as list of time.Duration.
+func GetDurationSlice(key string) (values []time.Duration, ok bool) {
+    return std.GetDurationSlice(key)
+}
+
+// MustGetDurationSlice returns the environment variable parsed as list of
+// time.Duration if possible, otherwise it panics.
+func MustGetDurationSlice(key string) (values []time.Duration) {
+    return std.MustGetDurationSlice(key)
+}
+
+// SetDefaultFloat64Slice sets the environment if it is not already set.
+func SetDefaultFloat64Slice(key string, values []float64) {
+    std.SetDefaultFloat64Slice(key, values)
+}
+
+// SetFloat64Slice sets the environment to the joined list of values.
+func SetFloat64Slice(key string, values []float64) {
+    std.SetFloat64Slice(key, values)
+}
+
+// LookupFloat64Slice returns the environment parsed as list of float64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupFloat64Slice(key string) (values []float64, err error) {
+    return std.LookupFloat64Slice(key)
+}
+
+// GetFloat64Slice returns the environment parsed as list of float64.
+func GetFloat64Slice(key string) (values []float64, ok bool) {
+    return std.GetFloat64Slice(key)
+}
+
+// MustGetFloat64Slice returns the environment variable parsed as list of
+// float64 if possible, otherwise it panics.
+func MustGetFloat64Slice(key string) (values []float64) {
+    return std.MustGetFloat64Slice(key)
+}
+
+// SetDefaultIntSlice sets the environment if it is not already set.
+func SetDefaultIntSlice(key string, values []int) {
+    std.SetDefaultIntSlice(key, values)
+}
+
+// SetIntSlice sets the environment to the joined list of values.
+func SetIntSlice(key string, values []int) {
+    std.SetIntSlice(key, values)
+}
+
+// LookupIntSlice returns the environment parsed as list of int.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupIntSlice(key string) (values []int, err error) {
+    return std.LookupIntSlice(key)
+}
+
+// GetIntSlice returns the environment parsed as list of int.
+func GetIntSlice(key string) (values []int, ok bool) {
+    return std.GetIntSlice(key)
+}
+
+// MustGetIntSlice returns the environment variable parsed as list of
+// int if possible, otherwise it panics.
+func MustGetIntSlice(key string) (values []int) {
+    return std.MustGetIntSlice(key)
+}
+
+// SetDefaultInt64Slice sets the environment if it is not already set.
+func SetDefaultInt64Slice(key string, values []int64) {
+    std.SetDefaultInt64Slice(key, values)
+}
+
+// SetInt64Slice sets the environment to the joined list of values.
+func SetInt64Slice(key string, values []int64) {
+    std.SetInt64Slice(key, values)
+}
+
+// LookupInt64Slice returns the environment parsed as list of int64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupInt64Slice(key string) (values []int64, err error) {
+    return std.LookupInt64Slice(key)
+}
+
+// GetInt64Slice returns the environment parsed as list of int64.
+func GetInt64Slice(key string) (values []int64, ok bool) {
+    return std.GetInt64Slice(key)
+}
+
+// MustGetInt64Slice returns the environment variable parsed as list of
+// int64 if possible, otherwise it panics.
+func MustGetInt64Slice(key string) (values []int64) {
+    return std.MustGetInt64Slice(key)
+}
+
+// SetDefaultUIntSlice sets the environment if it is not already set.
+func SetDefaultUIntSlice(key string, values []uint) {
+    std.SetDefaultUIntSlice(key, values)
+}
+
+// SetUIntSlice sets the environment to the joined list of values.
+func SetUIntSlice(key string, values []uint) {
+    std.SetUIntSlice(key, values)
+}
+
+// LookupUIntSlice returns the environment parsed as list of uint.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupUIntSlice(key string) (values []uint, err error) {
+    return std.LookupUIntSlice(key)
+}
+
+// GetUIntSlice returns the environment parsed as list of uint.
+func GetUIntSlice(key string) (values []uint, ok bool) {
+    return std.GetUIntSlice(key)
+}
+
+// MustGetUIntSlice returns the environment variable parsed as list of
+// uint if possible, otherwise it panics.
+func MustGetUIntSlice(key string) (values []uint) {
+    return std.MustGetUIntSlice(key)
+}
+
+// SetDefaultUInt64Slice sets the environment if it is not already set.
+func SetDefaultUInt64Slice(key string, values []uint64) {
+    std.SetDefaultUInt64Slice(key, values)
+}
+
+// SetUInt64Slice sets the environment to the joined list of values.
+func SetUInt64Slice(key string, values []uint64) {
+    std.SetUInt64Slice(key, values)
+}
+
+// LookupUInt64Slice returns the environment parsed as list of uint64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupUInt64Slice(key string) (values []uint64, err error) {
+    return std.LookupUInt64Slice(key)
+}
+
+// GetUInt64Slice returns the environment parsed as list of uint64.
+func GetUInt64Slice(key string) (values []uint64, ok bool) {
+    return std.GetUInt64Slice(key)
+}
+
+// MustGetUInt64Slice returns the environment variable parsed as list of
+// uint64 if possible, otherwise it panics.
+func MustGetUInt64Slice(key string) (values []uint64) {
+    return std.MustGetUInt64Slice(key)
+}
+
+// SetDefaultStringMap sets the environment if it is not already set.
+func SetDefaultStringMap(key string, values map[string]string) {
+    std.SetDefaultStringMap(key, values)
+}
+
+// SetStringMap sets the environment to the entries of values sorted by key.
+func SetStringMap(key string, values map[string]string) {
+    std.SetStringMap(key, values)
+}
+
+// LookupStringMap returns the environment parsed as map of string.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupStringMap(key string) (values map[string]string, err error) {
+    return std.LookupStringMap(key)
+}
+
+// GetStringMap returns the environment parsed as map of string.
+func GetStringMap(key string) (values map[string]string, ok bool) {
+    return std.GetStringMap(key)
+}
+
+// MustGetStringMap returns the environment variable parsed as map of
+// string if possible, otherwise it panics.
+func MustGetStringMap(key string) (values map[string]string) {
+    return std.MustGetStringMap(key)
+}
+
+// SetDefaultBoolMap sets the environment if it is not already set.
+func SetDefaultBoolMap(key string, values map[string]bool) {
+    std.SetDefaultBoolMap(key, values)
+}
+
+// SetBoolMap sets the environment to the entries of values sorted by key.
+func SetBoolMap(key string, values map[string]bool) {
+    std.SetBoolMap(key, values)
+}
+
+// LookupBoolMap returns the environment parsed as map of bool.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupBoolMap(key string) (values map[string]bool, err error) {
+    return std.LookupBoolMap(key)
+}
+
+// GetBoolMap returns the environment parsed as map of bool.
+func GetBoolMap(key string) (values map[string]bool, ok bool) {
+    return std.GetBoolMap(key)
+}
+
+// MustGetBoolMap returns the environment variable parsed as map of
+// bool if possible, otherwise it panics.
+func MustGetBoolMap(key string) (values map[string]bool) {
+    return std.MustGetBoolMap(key)
+}
+
+// SetDefaultDurationMap sets the environment if it is not already set.
+func SetDefaultDurationMap(key string, values map[string]time.Duration) {
+    std.SetDefaultDurationMap(key, values)
+}
+
+// SetDurationMap sets the environment to the entries of values sorted by key.
+func SetDurationMap(key string, values map[string]time.Duration) {
+    std.SetDurationMap(key, values)
+}
+
+// LookupDurationMap returns the environment parsed as map of time.Duration.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupDurationMap(key string) (values map[string]time.Duration, err error) {
+    return std.LookupDurationMap(key)
+}
+
+// GetDurationMap returns the environment parsed as map of time.Duration.
+func GetDurationMap(key string) (values map[string]time.Duration, ok bool) {
+    return std.GetDurationMap(key)
+}
+
+// MustGetDurationMap returns the environment variable parsed as map of
+// time.Duration if possible, otherwise it panics.
+func MustGetDurationMap(key string) (values map[string]time.Duration) {
+    return std.MustGetDurationMap(key)
+}
+
+// SetDefaultFloat64Map sets the environment if it is not already set.
+func SetDefaultFloat64Map(key string, values map[string]float64) {
+    std.SetDefaultFloat64Map(key, values)
+}
+
+// SetFloat64Map sets the environment to the entries of values sorted by key.
+func SetFloat64Map(key string, values map[string]float64) {
+    std.SetFloat64Map(key, values)
+}
+
+// LookupFloat64Map returns the environment parsed as map of float64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupFloat64Map(key string) (values map[string]float64, err error) {
+    return std.LookupFloat64Map(key)
+}
+
+// GetFloat64Map returns the environment parsed as map of float64.
+func GetFloat64Map(key string) (values map[string]float64, ok bool) {
+    return std.GetFloat64Map(key)
+}
+
+// MustGetFloat64Map returns the environment variable parsed as map of
+// float64 if possible, otherwise it panics.
+func MustGetFloat64Map(key string) (values map[string]float64) {
+    return std.MustGetFloat64Map(key)
+}
+
+// SetDefaultIntMap sets the environment if it is not already set.
+func SetDefaultIntMap(key string, values map[string]int) {
+    std.SetDefaultIntMap(key, values)
+}
+
+// SetIntMap sets the environment to the entries of values sorted by key.
+func SetIntMap(key string, values map[string]int) {
+    std.SetIntMap(key, values)
+}
+
+// LookupIntMap returns the environment parsed as map of int.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupIntMap(key string) (values map[string]int, err error) {
+    return std.LookupIntMap(key)
+}
+
+// GetIntMap returns the environment parsed as map of int.
+func GetIntMap(key string) (values map[string]int, ok bool) {
+    return std.GetIntMap(key)
+}
+
+// MustGetIntMap returns the environment variable parsed as map of
+// int if possible, otherwise it panics.
+func MustGetIntMap(key string) (values map[string]int) {
+    return std.MustGetIntMap(key)
+}
+
+// SetDefaultInt64Map sets the environment if it is not already set.
+func SetDefaultInt64Map(key string, values map[string]int64) {
+    std.SetDefaultInt64Map(key, values)
+}
+
+// SetInt64Map sets the environment to the entries of values sorted by key.
+func SetInt64Map(key string, values map[string]int64) {
+    std.SetInt64Map(key, values)
+}
+
+// LookupInt64Map returns the environment parsed as map of int64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupInt64Map(key string) (values map[string]int64, err error) {
+    return std.LookupInt64Map(key)
+}
+
+// GetInt64Map returns the environment parsed as map of int64.
+func GetInt64Map(key string) (values map[string]int64, ok bool) {
+    return std.GetInt64Map(key)
+}
+
+// MustGetInt64Map returns the environment variable parsed as map of
+// int64 if possible, otherwise it panics.
+func MustGetInt64Map(key string) (values map[string]int64) {
+    return std.MustGetInt64Map(key)
+}
+
+// SetDefaultUIntMap sets the environment if it is not already set.
+func SetDefaultUIntMap(key string, values map[string]uint) {
+    std.SetDefaultUIntMap(key, values)
+}
+
+// SetUIntMap sets the environment to the entries of values sorted by key.
+func SetUIntMap(key string, values map[string]uint) {
+    std.SetUIntMap(key, values)
+}
+
+// LookupUIntMap returns the environment parsed as map of uint.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupUIntMap(key string) (values map[string]uint, err error) {
+    return std.LookupUIntMap(key)
+}
+
+// GetUIntMap returns the environment parsed as map of uint.
+func GetUIntMap(key string) (values map[string]uint, ok bool) {
+    return std.GetUIntMap(key)
+}
+
+// MustGetUIntMap returns the environment variable parsed as map of
+// uint if possible, otherwise it panics.
+func MustGetUIntMap(key string) (values map[string]uint) {
+    return std.MustGetUIntMap(key)
+}
+
+// SetDefaultUInt64Map sets the environment if it is not already set.
+func SetDefaultUInt64Map(key string, values map[string]uint64) {
+    std.SetDefaultUInt64Map(key, values)
+}
+
+// SetUInt64Map sets the environment to the entries of values sorted by key.
+func SetUInt64Map(key string, values map[string]uint64) {
+    std.SetUInt64Map(key, values)
+}
+
+// LookupUInt64Map returns the environment parsed as map of uint64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func LookupUInt64Map(key string) (values map[string]uint64, err error) {
+    return std.LookupUInt64Map(key)
+}
+
+// GetUInt64Map returns the environment parsed as map of uint64.
+func GetUInt64Map(key string) (values map[string]uint64, ok bool) {
+    return std.GetUInt64Map(key)
+}
+
+// MustGetUInt64Map returns the environment variable parsed as map of
+// uint64 if possible, otherwise it panics.
+func MustGetUInt64Map(key string) (values map[string]uint64) {
+    return std.MustGetUInt64Map(key)
+}
+
+// GetText parses the environment variable into v using its UnmarshalText
+// method. See Env.GetText for details.
+func GetText(key string, v encoding.TextUnmarshaler) error {
+    return std.GetText(key, v)
+}
+
+// SetText sets the environment to the result of the MarshalText method of v.
+func SetText(key string, v encoding.TextMarshaler) error {
+    return std.SetText(key, v)
+}
+
+// SetFileFallback enables or disables the _FILE convention for the default
+// Env. See Env.SetFileFallback for details.
+func SetFileFallback(enabled bool) {
+    std.SetFileFallback(enabled)
+}
+
+// SetFileSizeLimit sets the maximum size in bytes of files read through the
+// _FILE convention by the default Env.
+func SetFileSizeLimit(limit int64) {
+    std.SetFileSizeLimit(limit)
+}
+
+// MarkSecret marks keys of the default Env as secret.
+// See Env.MarkSecret for details.
+func MarkSecret(keys ...string) {
+    std.MarkSecret(keys...)
+}
+
+// SetSecretPatterns sets the patterns identifying secret keys of the default
+// Env. See Env.SetSecretPatterns for details.
+func SetSecretPatterns(patterns ...string) error {
+    return std.SetSecretPatterns(patterns...)
+}
+
+// IsSecret reports whether key of the default Env is secret.
+func IsSecret(key string) bool {
+    return std.IsSecret(key)
+}
+
+// Dump writes all variables starting with the prefix of the default Env to
+// w. See Env.Dump for details.
+func Dump(w io.Writer) error {
+    return std.Dump(w)
+}
+
+// Describe returns the variables of the default Env bound to the fields of
+// spec. See Env.Describe for details.
+func Describe(spec interface{}) ([]*Variable, error) {
+    return std.Describe(spec)
+}
+
+// AddRules attaches rules to key of the default Env.
+// See Env.AddRules for details.
+func AddRules(key string, rules ...Rule) {
+    std.AddRules(key, rules...)
+}
+
+// Marshal stores every field of the struct pointed to by spec in the default
+// Env. See Env.Marshal for details.
+func Marshal(spec interface{}) error {
+    return std.Marshal(spec)
+}
+
+// MarshalEnvFile writes every field of the struct pointed to by spec in .env
+// syntax to w, using the keys of the default Env.
+func MarshalEnvFile(w io.Writer, spec interface{}) error {
+    return std.MarshalEnvFile(w, spec)
+}
+
+// Explain describes how the value of key is resolved by the Source of the
+// default Env. See Env.Explain for details.
+func Explain(key string) (Explanation, error) {
+    return std.Explain(key)
+}
+
+// BindFlags annotates the usage messages of the flags of fs with the keys of
+// the default Env. See Env.BindFlags for details.
+func BindFlags(fs *flag.FlagSet) {
+    std.BindFlags(fs)
+}
+
+// ParseFlags parses args with fs and sets every flag not passed in args from
+// the default Env. See Env.ParseFlags for details.
+func ParseFlags(fs *flag.FlagSet, args []string) error {
+    return std.ParseFlags(fs, args)
+}
+
+// JSONFileSource reads a JSON file into a FileSource using the keys of the
+// default Env. See Env.JSONSource for details.
+func JSONFileSource(path string) (*FileSource, error) {
+    return std.JSONFileSource(path)
+}
+
+// JSONSource reads a JSON object from r into a FileSource using the keys of
+// the default Env. See Env.JSONSource for details.
+func JSONSource(r io.Reader, name string) (*FileSource, error) {
+    return std.JSONSource(r, name)
+}
+
+// INIFileSource reads an INI file into a FileSource using the keys of the
+// default Env. See Env.INISource for details.
+func INIFileSource(path string) (*FileSource, error) {
+    return std.INIFileSource(path)
+}
+
+// INISource reads an INI document from r into a FileSource using the keys
+// of the default Env. See Env.INISource for details.
+func INISource(r io.Reader, name string) (*FileSource, error) {
+    return std.INISource(r, name)
+}
+
+// PropertiesFileSource reads a Java .properties file into a FileSource using
+// the keys of the default Env. See Env.PropertiesSource for details.
+func PropertiesFileSource(path string) (*FileSource, error) {
+    return std.PropertiesFileSource(path)
+}
+
+// PropertiesSource reads a Java .properties document from r into a
+// FileSource using the keys of the default Env. See Env.PropertiesSource
+// for details.
+func PropertiesSource(r io.Reader, name string) (*FileSource, error) {
+    return std.PropertiesSource(r, name)
+}
+
+// DirSource reads the files in the directory path into a DirectorySource
+// using the keys of the default Env. See Env.DirSource for details.
+func DirSource(path string, opts ...DirOption) (*DirectorySource, error) {
+    return std.DirSource(path, opts...)
+}
@@ -0,0 +1,161 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// DirOption configures DirSource.
+type DirOption func(*DirectorySource)
+
+// TrimNewlines removes all trailing newlines from the values read by
+// DirSource. By default the content of each file is used as it is.
+func TrimNewlines() DirOption {
+    return func(d *DirectorySource) {
+        d.trim = true
+    }
+}
+
+// DirectorySource is a read-only Source holding the files of a directory,
+// like the volumes Kubernetes mounts for ConfigMaps and Secrets. Each file
+// is a variable, its name the key and its content the value. It is safe for
+// concurrent use.
+type DirectorySource struct {
+    env  *Env
+    path string
+    trim bool
+
+    mu     sync.RWMutex
+    values map[string]string // key to value
+    files  map[string]string // key to path
+    target string            // target of the ..data symlink at the last scan
+}
+
+// DirSource reads the regular files in the directory path into a
+// DirectorySource whose keys are those e looks up. The file db-host is
+// stored under the key e.prepareKey("db host"), e.g. APP_DB_HOST.
+//
+// Files whose name starts with a dot are ignored, which includes the ..data
+// symlink and the timestamped directories Kubernetes uses to update a volume
+// atomically; symlinks pointing into them are followed. Subdirectories are
+// ignored as well. Files larger than the file size limit of e are rejected
+// with a *FileError wrapping ErrFileTooLarge.
+func (e *Env) DirSource(path string, opts ...DirOption) (*DirectorySource, error) {
+    d := &DirectorySource{env: e, path: path}
+    for _, opt := range opts {
+        opt(d)
+    }
+
+    if err := d.Rescan(); err != nil {
+        return nil, err
+    }
+
+    return d, nil
+}
+
+// Path returns the path of the directory.
+func (d *DirectorySource) Path() string {
+    return d.path
+}
+
+// Rescan reads the directory again and replaces all values at once. If
+// reading fails, the previous values are kept.
+func (d *DirectorySource) Rescan() error {
+    target, _ := os.Readlink(filepath.Join(d.path, "..data"))
+
+    entries, err := os.ReadDir(d.path)
+    if err != nil {
+        return err
+    }
+
+    values := make(map[string]string, len(entries))
+    files := make(map[string]string, len(entries))
+    for _, entry := range entries {
+        if strings.HasPrefix(entry.Name(), ".") {
+            continue
+        }
+
+        path := filepath.Join(d.path, entry.Name())
+        info, err := os.Stat(path)
+        if err != nil {
+            return err
+        }
+        if !info.Mode().IsRegular() {
+            continue
+        }
+
+        key := d.env.prepareKey(keyWords(entry.Name()))
+        v, err := readFileRaw(path, d.env.fileLimit)
+        if err != nil {
+            return &FileError{Key: key, Path: path, Err: err}
+        }
+        if d.trim {
+            v = strings.TrimRight(v, "\r\n")
+        }
+
+        values[key] = v
+        files[key] = path
+    }
+
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    d.values, d.files, d.target = values, files, target
+    return nil
+}
+
+// Refresh calls Rescan if the ..data symlink Kubernetes swaps on updates
+// points to a different target than at the last scan. It reports whether
+// the directory was read again.
+func (d *DirectorySource) Refresh() (bool, error) {
+    target, _ := os.Readlink(filepath.Join(d.path, "..data"))
+
+    d.mu.RLock()
+    changed := target != d.target
+    d.mu.RUnlock()
+
+    if !changed {
+        return false, nil
+    }
+
+    return true, d.Rescan()
+}
+
+// Lookup returns the value stored under key.
+func (d *DirectorySource) Lookup(key string) (string, bool) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    v, ok := d.values[key]
+    return v, ok
+}
+
+// Keys returns the sorted keys of d.
+func (d *DirectorySource) Keys() []string {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    keys := make([]string, 0, len(d.values))
+    for k := range d.values {
+        keys = append(keys, k)
+    }
+
+    sort.Strings(keys)
+    return keys
+}
+
+// Origin returns the path of the file key was read from.
+func (d *DirectorySource) Origin(key string) (Origin, bool) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+
+    path, ok := d.files[key]
+    return Origin{File: path}, ok
+}
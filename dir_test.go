@@ -0,0 +1,100 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+// writeTestVolume writes files the way Kubernetes populates a ConfigMap
+// volume: into a timestamped directory which the ..data symlink points to,
+// with a symlink per file pointing into ..data.
+func writeTestVolume(t *testing.T, dir, version string, files map[string]string) {
+    data := filepath.Join(dir, "..2024_01_01_"+version)
+    if err := os.Mkdir(data, 0700); err != nil {
+        t.Fatal(err)
+    }
+    for name, content := range files {
+        if err := os.WriteFile(filepath.Join(data, name), []byte(content), 0600); err != nil {
+            t.Fatal(err)
+        }
+        if _, err := os.Lstat(filepath.Join(dir, name)); err != nil {
+            if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil {
+                t.Fatal(err)
+            }
+        }
+    }
+
+    tmp := filepath.Join(dir, "..data_tmp")
+    if err := os.Symlink(filepath.Base(data), tmp); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Rename(tmp, filepath.Join(dir, "..data")); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestDirSource(t *testing.T) {
+    assert := assert.New(t)
+
+    dir := t.TempDir()
+    writeTestVolume(t, dir, "1", map[string]string{
+        "db-host":  "db.example.com\n",
+        "port":     "8080",
+        "password": "hunter2\r\n",
+    })
+    assert.NoError(os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0600))
+    assert.NoError(os.Mkdir(filepath.Join(dir, "sub"), 0700))
+
+    e := New("app")
+    src, err := e.DirSource(dir)
+    assert.NoError(err)
+    assert.Equal(dir, src.Path())
+    assert.Equal([]string{"APP_DB_HOST", "APP_PASSWORD", "APP_PORT"}, src.Keys())
+
+    v, ok := src.Lookup("APP_DB_HOST")
+    assert.True(ok)
+    assert.Equal("db.example.com\n", v)
+
+    src, err = e.DirSource(dir, TrimNewlines())
+    assert.NoError(err)
+    e.SetSource(src)
+    assert.Equal("db.example.com", e.MustGetString("db host"))
+    assert.Equal("hunter2", e.MustGetString("password"))
+    assert.Equal(8080, e.MustGetInt("port"))
+
+    origin, ok := src.Origin("APP_PORT")
+    assert.True(ok)
+    assert.Equal(Origin{File: filepath.Join(dir, "port")}, origin)
+    _, ok = src.Origin("APP_MISSING")
+    assert.False(ok)
+
+    changed, err := src.Refresh()
+    assert.NoError(err)
+    assert.False(changed)
+
+    writeTestVolume(t, dir, "2", map[string]string{"db-host": "replica", "port": "9090", "password": "x"})
+    changed, err = src.Refresh()
+    assert.NoError(err)
+    assert.True(changed)
+    assert.Equal(9090, e.MustGetInt("port"))
+    assert.Equal("replica", e.MustGetString("db host"))
+
+    _, err = e.DirSource(filepath.Join(dir, "missing"))
+    assert.Error(err)
+
+    e.SetFileSizeLimit(2)
+    _, err = e.DirSource(dir)
+    var ferr *FileError
+    assert.True(errors.As(err, &ferr))
+    assert.True(errors.Is(err, ErrFileTooLarge))
+    assert.Error(src.Rescan())
+    assert.Equal(9090, e.MustGetInt("port"))
+}
@@ -0,0 +1,336 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// SyntaxError is returned if a configuration file could not be parsed.
+type SyntaxError struct {
+    File string // name of the file, may be empty
+    Line int    // line number, starting at 1
+    Msg  string // description of the error
+}
+
+func (e *SyntaxError) Error() string {
+    name := e.File
+    if name == "" {
+        name = "<input>"
+    }
+
+    return name + ":" + strconv.Itoa(e.Line) + ": " + e.Msg
+}
+
+// LoadOption configures LoadFile and LoadReader.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+    override bool
+}
+
+// Override makes loaded values replace variables which are already set.
+// By default existing variables are kept, like SetDefaultString does.
+func Override() LoadOption {
+    return func(o *loadOptions) {
+        o.override = true
+    }
+}
+
+// LoadFile reads a .env file and stores its variables in the Source of e.
+// See LoadReader for details.
+func (e *Env) LoadFile(path string, opts ...LoadOption) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    return e.LoadReader(f, path, opts...)
+}
+
+// LoadReader reads variables in .env syntax from r and stores them in the
+// Source of e, which must implement Setter. The name is used in error
+// messages only.
+//
+// Keys are used as written in the file, neither normalized nor prefixed.
+// Each line has the form KEY=value, optionally preceded by "export". Values
+// may be unquoted, single-quoted (taken literally) or double-quoted (with
+// the escape sequences \n, \r, \t, \\, \", \' and \$). Quoted values may span
+// multiple lines. Blank lines and comments starting with # are ignored, a #
+// after whitespace starts an inline comment in unquoted values.
+//
+// Nothing is stored if the input contains a syntax error, which is reported
+// as a *SyntaxError.
+func (e *Env) LoadReader(r io.Reader, name string, opts ...LoadOption) error {
+    var o loadOptions
+    for _, opt := range opts {
+        opt(&o)
+    }
+
+    setter, ok := e.source.(Setter)
+    if !ok {
+        return errors.New("Source does not support setting environment variables")
+    }
+
+    entries, err := parseDotenv(r, name)
+    if err != nil {
+        return err
+    }
+
+    // Decide which keys to keep before storing anything, so that a key
+    // repeated in the file takes its last value like in DotenvSource.
+    keep := make(map[string]bool)
+    if !o.override {
+        for _, entry := range entries {
+            if _, ok := e.source.Lookup(entry.key); ok {
+                keep[entry.key] = true
+            }
+        }
+    }
+
+    for _, entry := range entries {
+        if keep[entry.key] {
+            continue
+        }
+
+        if err := setter.Set(entry.key, entry.value); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// DotenvFileSource reads a .env file into a FileSource, e.g. to use it as
+// layer of Layers. See DotenvSource for details.
+func DotenvFileSource(path string) (*FileSource, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    return DotenvSource(f, path)
+}
+
+// DotenvSource reads variables in .env syntax from r into a FileSource. The
+// syntax is the one accepted by LoadReader, keys are used as written. The
+// name is used in error messages and origins.
+func DotenvSource(r io.Reader, name string) (*FileSource, error) {
+    entries, err := parseDotenv(r, name)
+    if err != nil {
+        return nil, err
+    }
+
+    s := newFileSource(name)
+    for _, entry := range entries {
+        s.set(entry.key, entry.value, entry.line)
+    }
+
+    return s, nil
+}
+
+// dotenvEntry is a single variable read from a .env file.
+type dotenvEntry struct {
+    key   string
+    value string
+    line  int
+}
+
+// parseDotenv parses the .env file read from r.
+func parseDotenv(r io.Reader, name string) ([]dotenvEntry, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    p := &dotenvParser{src: string(data), name: name, line: 1}
+    return p.parse()
+}
+
+type dotenvParser struct {
+    src  string
+    pos  int
+    line int
+    name string
+}
+
+func (p *dotenvParser) errorf(line int, msg string) error {
+    return &SyntaxError{File: p.name, Line: line, Msg: msg}
+}
+
+func (p *dotenvParser) eof() bool {
+    return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+    return p.src[p.pos]
+}
+
+// next consumes and returns the next byte, keeping track of line numbers.
+func (p *dotenvParser) next() byte {
+    c := p.src[p.pos]
+    p.pos++
+    if c == '\n' {
+        p.line++
+    }
+
+    return c
+}
+
+// skipBlank skips spaces and tabs, but not line breaks.
+func (p *dotenvParser) skipBlank() {
+    for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+        p.pos++
+    }
+}
+
+// skipLine skips the rest of the current line including the line break.
+func (p *dotenvParser) skipLine() {
+    for !p.eof() && p.next() != '\n' {
+    }
+}
+
+func isKeyByte(c byte) bool {
+    return c == '_' || c == '.' || c == '-' ||
+        ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func (p *dotenvParser) readKey() string {
+    start := p.pos
+    for !p.eof() && isKeyByte(p.peek()) {
+        p.pos++
+    }
+
+    return p.src[start:p.pos]
+}
+
+func (p *dotenvParser) parse() ([]dotenvEntry, error) {
+    var entries []dotenvEntry
+
+    for {
+        for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+            p.next()
+        }
+        if p.eof() {
+            return entries, nil
+        }
+        if p.peek() == '#' {
+            p.skipLine()
+            continue
+        }
+
+        line := p.line
+        key := p.readKey()
+        if key == "export" && !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+            p.skipBlank()
+            key = p.readKey()
+        }
+        if key == "" {
+            return nil, p.errorf(line, "invalid key")
+        }
+
+        p.skipBlank()
+        if p.eof() || p.peek() != '=' {
+            return nil, p.errorf(line, "expected '=' after key "+strconv.Quote(key))
+        }
+        p.pos++
+        p.skipBlank()
+
+        value, err := p.readValue(line)
+        if err != nil {
+            return nil, err
+        }
+
+        entries = append(entries, dotenvEntry{key: key, value: value, line: line})
+    }
+}
+
+func (p *dotenvParser) readValue(line int) (string, error) {
+    if p.eof() {
+        return "", nil
+    }
+
+    var value string
+    switch p.peek() {
+    case '\'':
+        p.pos++
+        start := p.pos
+        for !p.eof() && p.peek() != '\'' {
+            p.next()
+        }
+        if p.eof() {
+            return "", p.errorf(line, "unterminated single-quoted value")
+        }
+        value = p.src[start:p.pos]
+        p.pos++
+    case '"':
+        p.pos++
+        var b strings.Builder
+        for {
+            if p.eof() {
+                return "", p.errorf(line, "unterminated double-quoted value")
+            }
+
+            c := p.next()
+            if c == '"' {
+                break
+            }
+            if c == '\\' && !p.eof() {
+                c = p.next()
+                switch c {
+                case 'n':
+                    c = '\n'
+                case 'r':
+                    c = '\r'
+                case 't':
+                    c = '\t'
+                case '\\', '"', '\'', '$':
+                default:
+                    b.WriteByte('\\')
+                }
+            }
+            b.WriteByte(c)
+        }
+        value = b.String()
+    default:
+        start := p.pos
+        for !p.eof() && p.peek() != '\n' {
+            if p.peek() == '#' && (p.pos == start || p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+                end := p.pos
+                p.skipLine()
+                return strings.TrimSpace(p.src[start:end]), nil
+            }
+            p.pos++
+        }
+
+        return strings.TrimSpace(p.src[start:p.pos]), nil
+    }
+
+    // Only blanks and a comment may follow a quoted value.
+    p.skipBlank()
+    if !p.eof() && p.peek() == '\r' {
+        p.pos++
+    }
+    if !p.eof() && p.peek() != '\n' && p.peek() != '#' {
+        return "", p.errorf(p.line, "unexpected character after quoted value")
+    }
+    p.skipLine()
+
+    return value, nil
+}
+
+// quoteDotenv returns value as double-quoted value in .env syntax.
+func quoteDotenv(value string) string {
+    return "\"" + dotenvEscaper.Replace(value) + "\""
+}
+
+var dotenvEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$",
+    "\n", "\\n", "\r", "\\r", "\t", "\\t")
@@ -0,0 +1,145 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+const dotenvTestInput = `# A comment
+PLAIN=value
+export EXPORTED=exported value
+  SPACED  =  spaced value  
+EMPTY=
+INLINE=value # a comment
+HASH=value#nocomment
+SINGLE='single $HOME \n # no comment'
+DOUBLE="double\ttab\nnewline \"quoted\" \$HOME \x"
+MULTI="first
+second"
+MULTI_SINGLE='a
+b' # trailing comment
+CRLF=crlf` + "\r\n" + `
+dotted.key=1
+LAST="last"`
+
+func TestParseDotenv(t *testing.T) {
+    assert := assert.New(t)
+
+    entries, err := parseDotenv(strings.NewReader(dotenvTestInput), "test.env")
+    if !assert.NoError(err) {
+        return
+    }
+
+    values := map[string]string{}
+    lines := map[string]int{}
+    for _, e := range entries {
+        values[e.key] = e.value
+        lines[e.key] = e.line
+    }
+
+    assert.Equal(map[string]string{
+        "PLAIN":        "value",
+        "EXPORTED":     "exported value",
+        "SPACED":       "spaced value",
+        "EMPTY":        "",
+        "INLINE":       "value",
+        "HASH":         "value#nocomment",
+        "SINGLE":       "single $HOME \\n # no comment",
+        "DOUBLE":       "double\ttab\nnewline \"quoted\" $HOME \\x",
+        "MULTI":        "first\nsecond",
+        "MULTI_SINGLE": "a\nb",
+        "CRLF":         "crlf",
+        "dotted.key":   "1",
+        "LAST":         "last",
+    }, values)
+
+    assert.Equal(2, lines["PLAIN"])
+    assert.Equal(10, lines["MULTI"])
+    assert.Equal(12, lines["MULTI_SINGLE"])
+    assert.Equal(16, lines["dotted.key"])
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+    assert := assert.New(t)
+
+    for input, line := range map[string]int{
+        "A=1\nB":               2,
+        "A=1\n=2":              2,
+        "A=1\n\nB 2":           3,
+        "A='unterminated\n\n":  1,
+        "A=1\nB=\"open\nC=3":   2,
+        "A=\"x\" trailing":     1,
+        "A=1\n# c\n$B=1":       3,
+        "export\nA=1":          1,
+    } {
+        _, err := parseDotenv(strings.NewReader(input), "broken.env")
+        var serr *SyntaxError
+        if assert.True(errors.As(err, &serr), input) {
+            assert.Equal("broken.env", serr.File)
+            assert.Equal(line, serr.Line, input)
+            assert.True(strings.HasPrefix(err.Error(), "broken.env:"), input)
+        }
+    }
+
+    _, err := parseDotenv(strings.NewReader("A"), "")
+    assert.True(strings.HasPrefix(err.Error(), "<input>:1: "))
+}
+
+func TestLoadReader(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_PORT": "80"}))
+
+    assert.NoError(e.LoadReader(strings.NewReader("APP_PORT=8080\nAPP_HOST=localhost"), ""))
+    assert.Equal(80, e.MustGetInt("port"))
+    assert.Equal("localhost", e.MustGetString("host"))
+
+    // The last value of a repeated key wins, like in DotenvSource
+    assert.NoError(e.LoadReader(strings.NewReader("APP_A=1\nAPP_A=2\nAPP_PORT=1\nAPP_PORT=2"), ""))
+    assert.Equal("2", e.MustGetString("a"))
+    assert.Equal(80, e.MustGetInt("port"))
+    src, err := DotenvSource(strings.NewReader("APP_A=1\nAPP_A=2"), "")
+    assert.NoError(err)
+    v, _ := src.Lookup("APP_A")
+    assert.Equal("2", v)
+
+    assert.NoError(e.LoadReader(strings.NewReader("APP_PORT=8080\nAPP_HOST=example.com"), "", Override()))
+    assert.Equal(8080, e.MustGetInt("port"))
+    assert.Equal("example.com", e.MustGetString("host"))
+
+    // Nothing is stored on errors
+    assert.Error(e.LoadReader(strings.NewReader("APP_DEBUG=1\nbroken"), ""))
+    assert.False(e.IssetKey("debug"))
+
+    e.SetSource(LookupFunc(func(string) (string, bool) { return "", false }))
+    assert.Error(e.LoadReader(strings.NewReader("APP_DEBUG=1"), ""))
+}
+
+func TestLoadFile(t *testing.T) {
+    assert := assert.New(t)
+
+    path := filepath.Join(t.TempDir(), ".env")
+    assert.NoError(os.WriteFile(path, []byte("ENVCONF_DOTENV_TEST=\"from file\"\n"), 0600))
+
+    UnsetKey("envconf_dotenv_test")
+    defer UnsetKey("envconf_dotenv_test")
+
+    assert.NoError(LoadFile(path))
+    assert.Equal("from file", MustGetString("envconf_dotenv_test"))
+
+    assert.Error(LoadFile(filepath.Join(t.TempDir(), "missing.env")))
+
+    assert.NoError(os.WriteFile(path, []byte("OK=1\nbroken\n"), 0600))
+    err := LoadFile(path)
+    assert.Equal(path+":2: expected '=' after key \"broken\"", err.Error())
+}
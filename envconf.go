@@ -5,24 +5,55 @@
 package envconf
 
 import (
-    "log"
-    "os"
+    "errors"
     "strconv"
     "strings"
+    "sync"
     "time"
 )
 
-var prefix = ""
+// Env provides access to environment variables using its own prefix, key
+// normalization and Source. The zero value is not usable, create instances
+// with New.
+type Env struct {
+    prefix  string
+    keyFunc func(key string) string
+    source  Source
+    expand  bool
+    list    ListOptions
 
-func prepareKey(key string) string {
+    fileFallback bool
+    fileLimit    int64
+
+    mu             sync.RWMutex // guards secrets, secretPatterns and rules
+    secrets        map[string]bool
+    secretPatterns []string
+    rules          map[string][]Rule
+}
+
+// New returns a new Env which reads the process environment and prepends
+// the given prefix to every key.
+func New(prefix string) *Env {
+    e := &Env{keyFunc: normalizeKey, source: OSEnv{}, list: defaultListOptions,
+        fileLimit: DefaultFileSizeLimit, secretPatterns: DefaultSecretPatterns}
+    e.SetPrefix(prefix)
+    return e
+}
+
+func normalizeKey(key string) string {
     key = strings.TrimSpace(strings.ToUpper(key))
     for strings.Contains(key, "  ") {
         key = strings.Replace(key, "  ", " ", -1)
     }
-    key = strings.Replace(key, " ", "_", -1)
 
-    if key != "" && prefix != "" {
-        key = prefix + key
+    return strings.Replace(key, " ", "_", -1)
+}
+
+func (e *Env) prepareKey(key string) string {
+    key = e.keyFunc(key)
+
+    if key != "" && e.prefix != "" {
+        key = e.prefix + key
     }
 
     return key
@@ -39,379 +70,423 @@ func parseBool(str string) (value bool, ok bool) {
     return false, false
 }
 
-// GetPrefix returns the prefix that is automatically prepended to a environment variable.
-func GetPrefix() string {
-    return prefix
+func parseBoolError(str string) (bool, error) {
+    if v, ok := parseBool(str); ok {
+        return v, nil
+    }
+
+    return false, strconv.ErrSyntax
 }
 
-// SetPrefix sets the prefix which is automatically prepended to an environment variable.
-func SetPrefix(p string) {
-    p = strings.TrimSpace(strings.ToUpper(p))
-    if p != "" {
-        for strings.Contains(p, "  ") {
-            p = strings.Replace(p, "  ", " ", -1)
-        }
-        p = strings.Replace(p, " ", "_", -1)
+func parseDuration(str string) (time.Duration, error) {
+    return time.ParseDuration(str)
+}
 
-        if !strings.HasSuffix(p, "_") {
-            p += "_"
-        }
-    }
+func parseFloat64(str string) (float64, error) {
+    return strconv.ParseFloat(str, 64)
+}
 
-    prefix = p
+func parseInt(str string) (int, error) {
+    v, err := strconv.ParseInt(str, 10, 32)
+    return int(v), err
 }
 
-// UnsetKey unsets a single environment variable.
-func UnsetKey(key string) {
-    os.Unsetenv(prepareKey(key))
+func parseInt64(str string) (int64, error) {
+    return strconv.ParseInt(str, 10, 64)
 }
 
-// IssetKey determine if a environment variable is set.
-func IssetKey(key string) bool {
-    _, ok := os.LookupEnv(prepareKey(key))
-    return ok
+func parseUInt(str string) (uint, error) {
+    v, err := strconv.ParseUint(str, 10, 32)
+    return uint(v), err
 }
 
-// SetDefaultString sets the environment if it is not already set.
-func SetDefaultString(key string, value string) {
-    if !IssetKey(key) {
-        SetString(key, value)
-    }
+func parseUInt64(str string) (uint64, error) {
+    return strconv.ParseUint(str, 10, 64)
 }
 
-// SetString sets the environment.
-func SetString(key string, value string) {
-    os.Setenv(prepareKey(key), value)
+func parseString(str string) (string, error) {
+    return str, nil
 }
 
-// GetString returns the environment parsed as string.
-// Returns an empty string if the environment does not exists or could not be
-// parsed.
-func GetString(key string) (value string, ok bool) {
-    key = prepareKey(key)
+func formatString(value string) string {
+    return value
+}
+
+func formatBool(value bool) string {
+    return strconv.FormatBool(value)
+}
+
+func formatDuration(value time.Duration) string {
+    return value.String()
+}
+
+func formatFloat64(value float64) string {
+    return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatInt(value int) string {
+    return strconv.FormatInt(int64(value), 10)
+}
+
+func formatInt64(value int64) string {
+    return strconv.FormatInt(value, 10)
+}
+
+func formatUInt(value uint) string {
+    return strconv.FormatUint(uint64(value), 10)
+}
+
+func formatUInt64(value uint64) string {
+    return strconv.FormatUint(value, 10)
+}
+
+// GetPrefix returns the prefix that is automatically prepended to a environment variable.
+func (e *Env) GetPrefix() string {
+    return e.prefix
+}
 
-    if v, ok := os.LookupEnv(key); ok {
-        return v, true
+// SetPrefix sets the prefix which is automatically prepended to an environment variable.
+func (e *Env) SetPrefix(p string) {
+    p = normalizeKey(p)
+    if p != "" && !strings.HasSuffix(p, "_") {
+        p += "_"
     }
 
-    return "", false
+    e.prefix = p
 }
 
-// MustGetString returns the environment variable parsed as string
-// if possible, otherwise it panics.
-func MustGetString(key string) (value string) {
-    value, ok := GetString(key)
-    if !ok {
-        panic("Environment variable \"" + prepareKey(key) + "\" not found")
+// SetKeyFunc sets the function used to normalize keys before the prefix is
+// prepended. A nil function restores the default normalization, which
+// converts the key to upper case and replaces whitespace by underscores.
+func (e *Env) SetKeyFunc(f func(key string) string) {
+    if f == nil {
+        f = normalizeKey
     }
 
-    return value
+    e.keyFunc = f
 }
 
-// SetDefaultBool sets the environment if it is not already set.
-func SetDefaultBool(key string, value bool) {
-    if !IssetKey(key) {
-        SetBool(key, value)
+// SetLookupFunc sets the function used to look up environment variables.
+// It is a shorthand for SetSource(LookupFunc(f)), a nil function restores
+// the process environment.
+func (e *Env) SetLookupFunc(f func(key string) (string, bool)) {
+    if f == nil {
+        e.SetSource(nil)
+        return
     }
+
+    e.SetSource(LookupFunc(f))
 }
 
-// SetBool sets the environment.
-func SetBool(key string, value bool) {
-    SetString(key, strconv.FormatBool(value))
+// GetSource returns the Source values are looked up from.
+func (e *Env) GetSource() Source {
+    return e.source
 }
 
-// GetBool ...
-func GetBool(key string) (value bool, ok bool) {
-    str, ok := GetString(key)
-    if ok {
-        return parseBool(str)
+// SetSource sets the Source values are looked up from. A nil Source restores
+// the process environment.
+func (e *Env) SetSource(s Source) {
+    if s == nil {
+        s = OSEnv{}
     }
 
-    return false, true
+    e.source = s
 }
 
-// MustGetBool returns the environment variable parsed as bool
-// if possible, otherwise it panics.
-func MustGetBool(key string) (value bool) {
-    str, ok := GetString(key)
-    if ok {
-        if value, ok := parseBool(str); ok {
-            return value
-        }
+// UnsetKey unsets a single environment variable.
+// It has no effect if the Source does not implement Unsetter.
+func (e *Env) UnsetKey(key string) {
+    if u, ok := e.source.(Unsetter); ok {
+        u.Unset(e.prepareKey(key))
+    }
+}
 
-        panic("Can not convert environment variable \"" +
-            prepareKey(key) + "\" to type boolean")
+// IssetKey determine if a environment variable is set.
+// With the _FILE convention enabled, a set KEY_FILE counts as well.
+func (e *Env) IssetKey(key string) bool {
+    key = e.prepareKey(key)
+    if _, ok := e.source.Lookup(key); ok {
+        return true
+    }
+
+    if e.fileFallback {
+        _, ok := e.source.Lookup(key + "_FILE")
+        return ok
     }
 
     return false
 }
 
-// SetDefaultDuration sets the environment if it is not already set.
-func SetDefaultDuration(key string, value time.Duration) {
-    if !IssetKey(key) {
-        SetDuration(key, value)
+// SetDefaultString sets the environment if it is not already set.
+func (e *Env) SetDefaultString(key string, value string) {
+    DefaultIn(e, key, value)
+}
+
+// SetString sets the environment.
+// It has no effect if the Source does not implement Setter.
+func (e *Env) SetString(key string, value string) {
+    if s, ok := e.source.(Setter); ok {
+        s.Set(e.prepareKey(key), value)
     }
 }
 
-// SetDuration sets the environment.
-func SetDuration(key string, value time.Duration) {
-    SetString(key, value.String())
+// LookupString returns the environment as string or an error matching
+// ErrNotSet if it does not exist. If expansion is enabled, failures to
+// expand the value are reported as *ExpandError, with the _FILE convention
+// enabled failures to read the file as *FileError. Values read from files
+// are never expanded. Violated rules are reported as *RuleError.
+func (e *Env) LookupString(key string) (value string, err error) {
+    return GetFrom[string](e, key)
 }
 
-// GetDuration returns the environment parsed as time.Duration.
-func GetDuration(key string) (value time.Duration, ok bool) {
-    str, ok := GetString(key)
+// lookup returns the raw value of key, see LookupString.
+func (e *Env) lookup(key string) (string, error) {
+    key = e.prepareKey(key)
 
-    if ok {
-        v, err := time.ParseDuration(str)
-        if err == nil {
-            return v, true
+    v, ok := e.source.Lookup(key)
+    if e.fileFallback {
+        fv, fok, err := e.lookupFile(key, ok)
+        if err != nil {
+            return "", err
         }
+        if fok {
+            return fv, nil
+        }
+    }
+    if !ok {
+        return "", &notSetError{key}
+    }
 
-        log.Println(err)
+    if e.expand {
+        return e.expandValue(key, v, []string{key})
     }
 
-    return 0, false
+    return v, nil
 }
 
-// MustGetDuration returns the environment variable parsed as time.Duration
+// GetString returns the environment parsed as string.
+// Returns an empty string if the environment does not exists or could not be
+// parsed.
+func (e *Env) GetString(key string) (value string, ok bool) {
+    value, err := e.LookupString(key)
+    return value, logError(err)
+}
+
+// MustGetString returns the environment variable parsed as string
 // if possible, otherwise it panics.
-func MustGetDuration(key string) time.Duration {
-    str, ok := GetString(key)
+func (e *Env) MustGetString(key string) (value string) {
+    return MustGetFrom[string](e, key)
+}
 
-    if ok {
-        v, err := time.ParseDuration(str)
-        if err == nil {
-            return v
-        }
+// SetDefaultBool sets the environment if it is not already set.
+func (e *Env) SetDefaultBool(key string, value bool) {
+    DefaultIn(e, key, value)
+}
 
-        panic("Failed to parse duration from environment variable \"" + prepareKey(key) + "\" with error: " + err.Error())
-    }
+// SetBool sets the environment.
+func (e *Env) SetBool(key string, value bool) {
+    SetIn(e, key, value)
+}
 
-    panic("Environment variable \"" + prepareKey(key) + "\" not found")
+// LookupBool returns the environment parsed as bool.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupBool(key string) (value bool, err error) {
+    return GetFrom[bool](e, key)
 }
 
-// SetDefaultFloat64 sets the environment if it is not already set.
-func SetDefaultFloat64(key string, value float64) {
-    if !IssetKey(key) {
-        SetFloat64(key, value)
+// GetBool returns the environment parsed as bool. A not existing environment
+// variable is considered to be false, an empty one to be true.
+func (e *Env) GetBool(key string) (value bool, ok bool) {
+    value, err := e.LookupBool(key)
+    if errors.Is(err, ErrNotSet) {
+        return false, true
     }
+
+    return value, logError(err)
 }
 
-// SetFloat64 sets the environment.
-func SetFloat64(key string, value float64) {
-    SetString(key, strconv.FormatFloat(value, 'f', -1, 64))
+// MustGetBool returns the environment variable parsed as bool
+// if possible, otherwise it panics.
+func (e *Env) MustGetBool(key string) (value bool) {
+    value, err := e.LookupBool(key)
+    if err != nil && !errors.Is(err, ErrNotSet) {
+        panic(err.Error())
+    }
+
+    return value
 }
 
-// GetFloat64 returns the environment parsed as float64.
-func GetFloat64(key string) (value float64, ok bool) {
-    str, ok := GetString(key)
+// SetDefaultDuration sets the environment if it is not already set.
+func (e *Env) SetDefaultDuration(key string, value time.Duration) {
+    DefaultIn(e, key, value)
+}
 
-    if ok {
-        v, err := strconv.ParseFloat(str, 64)
-        if err == nil {
-            return v, true
-        }
+// SetDuration sets the environment.
+func (e *Env) SetDuration(key string, value time.Duration) {
+    SetIn(e, key, value)
+}
 
-        log.Println(err)
-    }
+// LookupDuration returns the environment parsed as time.Duration.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupDuration(key string) (value time.Duration, err error) {
+    return GetFrom[time.Duration](e, key)
+}
 
-    return 0, false
+// GetDuration returns the environment parsed as time.Duration.
+func (e *Env) GetDuration(key string) (value time.Duration, ok bool) {
+    value, err := e.LookupDuration(key)
+    return value, logError(err)
 }
 
-// MustGetFloat64 returns the environment variable parsed as float64
+// MustGetDuration returns the environment variable parsed as time.Duration
 // if possible, otherwise it panics.
-func MustGetFloat64(key string) float64 {
-    str, ok := GetString(key)
+func (e *Env) MustGetDuration(key string) (value time.Duration) {
+    return MustGetFrom[time.Duration](e, key)
+}
 
-    if ok {
-        v, err := strconv.ParseFloat(str, 64)
-        if err == nil {
-            return v
-        }
+// SetDefaultFloat64 sets the environment if it is not already set.
+func (e *Env) SetDefaultFloat64(key string, value float64) {
+    DefaultIn(e, key, value)
+}
 
-        panic("Failed to parse float64 from environment variable \"" + prepareKey(key) + "\" with error: " + err.Error())
-    }
+// SetFloat64 sets the environment.
+func (e *Env) SetFloat64(key string, value float64) {
+    SetIn(e, key, value)
+}
 
-    panic("Environment variable \"" + prepareKey(key) + "\" not found")
+// LookupFloat64 returns the environment parsed as float64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupFloat64(key string) (value float64, err error) {
+    return GetFrom[float64](e, key)
 }
 
-// SetDefaultInt sets the environment if it is not already set.
-func SetDefaultInt(key string, value int) {
-    if !IssetKey(key) {
-        SetInt(key, value)
-    }
+// GetFloat64 returns the environment parsed as float64.
+func (e *Env) GetFloat64(key string) (value float64, ok bool) {
+    value, err := e.LookupFloat64(key)
+    return value, logError(err)
 }
 
-// SetInt sets the environment.
-func SetInt(key string, value int) {
-    SetString(key, strconv.FormatInt(int64(value), 10))
+// MustGetFloat64 returns the environment variable parsed as float64
+// if possible, otherwise it panics.
+func (e *Env) MustGetFloat64(key string) (value float64) {
+    return MustGetFrom[float64](e, key)
 }
 
-// GetInt returns the environment parsed as int.
-func GetInt(key string) (value int, ok bool) {
-    str, ok := GetString(key)
+// SetDefaultInt sets the environment if it is not already set.
+func (e *Env) SetDefaultInt(key string, value int) {
+    DefaultIn(e, key, value)
+}
 
-    if ok {
-        v, err := strconv.ParseInt(str, 10, 32)
-        if err == nil {
-            return int(v), true
-        }
+// SetInt sets the environment.
+func (e *Env) SetInt(key string, value int) {
+    SetIn(e, key, value)
+}
 
-        log.Println(err)
-    }
+// LookupInt returns the environment parsed as int.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupInt(key string) (value int, err error) {
+    return GetFrom[int](e, key)
+}
 
-    return 0, false
+// GetInt returns the environment parsed as int.
+func (e *Env) GetInt(key string) (value int, ok bool) {
+    value, err := e.LookupInt(key)
+    return value, logError(err)
 }
 
 // MustGetInt returns the environment variable parsed as int
 // if possible, otherwise it panics.
-func MustGetInt(key string) (value int) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseInt(str, 10, 32)
-        if err == nil {
-            return int(v)
-        }
-
-        panic("Failed to parse int from environment variable \"" + prepareKey(key) + "\" with error: " + err.Error())
-    }
-
-    panic("Environment variable \"" + prepareKey(key) + "\" not found")
+func (e *Env) MustGetInt(key string) (value int) {
+    return MustGetFrom[int](e, key)
 }
 
 // SetDefaultInt64 sets the environment if it is not already set.
-func SetDefaultInt64(key string, value int64) {
-    if !IssetKey(key) {
-        SetInt64(key, value)
-    }
+func (e *Env) SetDefaultInt64(key string, value int64) {
+    DefaultIn(e, key, value)
 }
 
 // SetInt64 sets the environment.
-func SetInt64(key string, value int64) {
-    SetString(key, strconv.FormatInt(value, 10))
+func (e *Env) SetInt64(key string, value int64) {
+    SetIn(e, key, value)
 }
 
-// GetInt64 returns the environment parsed as int64.
-func GetInt64(key string) (value int64, ok bool) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseInt(str, 10, 64)
-        if err == nil {
-            return v, true
-        }
-
-        log.Println(err)
-    }
+// LookupInt64 returns the environment parsed as int64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupInt64(key string) (value int64, err error) {
+    return GetFrom[int64](e, key)
+}
 
-    return 0, false
+// GetInt64 returns the environment parsed as int64.
+func (e *Env) GetInt64(key string) (value int64, ok bool) {
+    value, err := e.LookupInt64(key)
+    return value, logError(err)
 }
 
 // MustGetInt64 returns the environment variable parsed as int64
 // if possible, otherwise it panics.
-func MustGetInt64(key string) (value int64) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseInt(str, 10, 64)
-        if err == nil {
-            return v
-        }
-
-        panic("Failed to parse int64 from environment variable \"" + prepareKey(key) + "\" with error: " + err.Error())
-    }
-
-    panic("Environment variable \"" + prepareKey(key) + "\" not found")
+func (e *Env) MustGetInt64(key string) (value int64) {
+    return MustGetFrom[int64](e, key)
 }
 
 // SetDefaultUInt sets the environment if it is not already set.
-func SetDefaultUInt(key string, value uint) {
-    if !IssetKey(key) {
-        SetUInt(key, value)
-    }
+func (e *Env) SetDefaultUInt(key string, value uint) {
+    DefaultIn(e, key, value)
 }
 
 // SetUInt sets the environment.
-func SetUInt(key string, value uint) {
-    SetString(key, strconv.FormatUint(uint64(value), 10))
+func (e *Env) SetUInt(key string, value uint) {
+    SetIn(e, key, value)
 }
 
-// GetUInt returns the environment parsed as uint.
-func GetUInt(key string) (value uint, ok bool) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseUint(str, 10, 32)
-        if err == nil {
-            return uint(v), true
-        }
-
-        log.Println(err)
-    }
+// LookupUInt returns the environment parsed as uint.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupUInt(key string) (value uint, err error) {
+    return GetFrom[uint](e, key)
+}
 
-    return 0, false
+// GetUInt returns the environment parsed as uint.
+func (e *Env) GetUInt(key string) (value uint, ok bool) {
+    value, err := e.LookupUInt(key)
+    return value, logError(err)
 }
 
 // MustGetUInt returns the environment variable parsed as uint
 // if possible, otherwise it panics.
-func MustGetUInt(key string) (value uint) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseUint(str, 10, 32)
-        if err == nil {
-            return uint(v)
-        }
-
-        panic("Failed to parse uint from environment variable \"" + prepareKey(key) + "\" with error: " + err.Error())
-    }
-
-    panic("Environment variable \"" + prepareKey(key) + "\" not found")
+func (e *Env) MustGetUInt(key string) (value uint) {
+    return MustGetFrom[uint](e, key)
 }
 
 // SetDefaultUInt64 sets the environment if it is not already set.
-func SetDefaultUInt64(key string, value uint64) {
-    if !IssetKey(key) {
-        SetUInt64(key, value)
-    }
+func (e *Env) SetDefaultUInt64(key string, value uint64) {
+    DefaultIn(e, key, value)
 }
 
 // SetUInt64 sets the environment.
-func SetUInt64(key string, value uint64) {
-    SetString(key, strconv.FormatUint(value, 10))
+func (e *Env) SetUInt64(key string, value uint64) {
+    SetIn(e, key, value)
 }
 
-// GetUInt64 returns the environment parsed as uint64.
-func GetUInt64(key string) (value uint64, ok bool) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseUint(str, 10, 64)
-        if err == nil {
-            return v, true
-        }
-
-        log.Println(err)
-    }
+// LookupUInt64 returns the environment parsed as uint64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupUInt64(key string) (value uint64, err error) {
+    return GetFrom[uint64](e, key)
+}
 
-    return 0, false
+// GetUInt64 returns the environment parsed as uint64.
+func (e *Env) GetUInt64(key string) (value uint64, ok bool) {
+    value, err := e.LookupUInt64(key)
+    return value, logError(err)
 }
 
 // MustGetUInt64 returns the environment variable parsed as uint64
 // if possible, otherwise it panics.
-func MustGetUInt64(key string) (value uint64) {
-    str, ok := GetString(key)
-
-    if ok {
-        v, err := strconv.ParseUint(str, 10, 64)
-        if err == nil {
-            return v
-        }
-
-        panic("Failed to parse uint64 from environment variable \"" + prepareKey(key) + "\" with error: " + err.Error())
-    }
-
-    panic("Environment variable \"" + prepareKey(key) + "\" not found")
+func (e *Env) MustGetUInt64(key string) (value uint64) {
+    return MustGetFrom[uint64](e, key)
 }
@@ -5,6 +5,7 @@
 package envconf
 
 import (
+    "strings"
     "testing"
     "time"
 
@@ -448,3 +449,60 @@ func TestUInt64(t *testing.T) {
     assert.Equal(uint64(0), v)
     assert.Panics(func() { MustGetUInt64("envconf_test2") })
 }
+
+func TestEnv(t *testing.T) {
+    assert := assert.New(t)
+
+    a := New("envconf a")
+    b := New("envconf_b_")
+    assert.Equal("ENVCONF_A_", a.GetPrefix())
+    assert.Equal("ENVCONF_B_", b.GetPrefix())
+    assert.Empty(GetPrefix())
+    assert.Equal(std, DefaultEnv())
+
+    a.SetInt("port", 80)
+    b.SetInt("port", 443)
+    assert.Equal(80, a.MustGetInt("port"))
+    assert.Equal(443, b.MustGetInt("port"))
+    assert.Equal(80, MustGetInt("envconf_a_port"))
+    assert.False(IssetKey("port"))
+
+    a.UnsetKey("port")
+    b.UnsetKey("port")
+    assert.False(a.IssetKey("port"))
+    assert.False(b.IssetKey("port"))
+}
+
+func TestEnvKeyFunc(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("envconf")
+    e.SetKeyFunc(func(key string) string {
+        return strings.Replace(normalizeKey(key), ".", "_", -1)
+    })
+    assert.Equal("ENVCONF_DB_HOST", e.prepareKey("db.host"))
+
+    e.SetKeyFunc(nil)
+    assert.Equal("ENVCONF_DB.HOST", e.prepareKey("db.host"))
+}
+
+func TestEnvLookupFunc(t *testing.T) {
+    assert := assert.New(t)
+
+    values := map[string]string{"APP_PORT": "8080", "APP_DEBUG": "yes"}
+
+    e := New("app")
+    e.SetLookupFunc(func(key string) (string, bool) {
+        v, ok := values[key]
+        return v, ok
+    })
+
+    assert.True(e.IssetKey("port"))
+    assert.Equal(8080, e.MustGetInt("port"))
+    assert.True(e.MustGetBool("debug"))
+    assert.False(e.IssetKey("timeout"))
+    assert.Panics(func() { e.MustGetDuration("timeout") })
+
+    e.SetLookupFunc(nil)
+    assert.False(e.IssetKey("port"))
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "log"
+    "strconv"
+)
+
+// ErrNotSet is returned by the Lookup functions if an environment variable
+// is not set. The returned error includes the key and must be tested with
+// errors.Is.
+var ErrNotSet = errors.New("environment variable not set")
+
+// notSetError is the error returned for a specific missing key.
+type notSetError struct {
+    key string
+}
+
+func (e *notSetError) Error() string {
+    return "Environment variable \"" + e.key + "\" not found"
+}
+
+func (e *notSetError) Is(target error) bool {
+    return target == ErrNotSet
+}
+
+// ParseError is returned if an environment variable could not be converted
+// to the requested type. For secret keys Value is Redacted and Err is
+// replaced by an error which does not contain the value.
+type ParseError struct {
+    Key   string // normalized and prefixed key
+    Value string // raw value of the environment variable
+    Type  string // name of the requested type, e.g. "int" or "time.Duration"
+    Err   error  // underlying error, e.g. from strconv or time
+
+    secret bool
+}
+
+func (e *ParseError) Error() string {
+    reason := e.Err.Error()
+    if e.secret {
+        reason = e.reason()
+    }
+
+    return "Failed to parse " + e.Type + " from environment variable \"" +
+        e.Key + "\" with error: " + reason
+}
+
+// reason returns a short description of the underlying error which does
+// not contain the value of secret keys.
+func (e *ParseError) reason() string {
+    var nerr *strconv.NumError
+    if errors.As(e.Err, &nerr) {
+        return nerr.Err.Error()
+    }
+    if e.secret {
+        return "invalid value"
+    }
+
+    return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+    return e.Err
+}
+
+// logError logs err unless it is nil or reports a missing variable.
+// It reports whether err is nil.
+func logError(err error) bool {
+    if err == nil {
+        return true
+    }
+
+    if !errors.Is(err, ErrNotSet) {
+        log.Println(err)
+    }
+
+    return false
+}
@@ -0,0 +1,130 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strconv"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("envconf")
+    e.SetSource(NewMapSource(nil))
+
+    _, err := e.LookupString("lookup")
+    assert.True(errors.Is(err, ErrNotSet))
+    assert.Equal("Environment variable \"ENVCONF_LOOKUP\" not found", err.Error())
+
+    _, err = e.LookupBool("lookup")
+    assert.True(errors.Is(err, ErrNotSet))
+    _, err = e.LookupInt("lookup")
+    assert.True(errors.Is(err, ErrNotSet))
+    _, err = e.LookupDuration("lookup")
+    assert.True(errors.Is(err, ErrNotSet))
+
+    e.SetString("lookup", "12")
+    s, err := e.LookupString("lookup")
+    assert.NoError(err)
+    assert.Equal("12", s)
+    i, err := e.LookupInt("lookup")
+    assert.NoError(err)
+    assert.Equal(12, i)
+    i64, err := e.LookupInt64("lookup")
+    assert.NoError(err)
+    assert.Equal(int64(12), i64)
+    u, err := e.LookupUInt("lookup")
+    assert.NoError(err)
+    assert.Equal(uint(12), u)
+    u64, err := e.LookupUInt64("lookup")
+    assert.NoError(err)
+    assert.Equal(uint64(12), u64)
+    f, err := e.LookupFloat64("lookup")
+    assert.NoError(err)
+    assert.True(floatEquals(12, f))
+    _, err = e.LookupBool("lookup")
+    assert.Error(err)
+
+    e.SetDuration("lookup", time.Minute)
+    d, err := e.LookupDuration("lookup")
+    assert.NoError(err)
+    assert.Equal(time.Minute, d)
+
+    e.SetBool("lookup", true)
+    b, err := e.LookupBool("lookup")
+    assert.NoError(err)
+    assert.True(b)
+}
+
+func TestParseError(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("envconf")
+    e.SetSource(NewMapSource(map[string]string{"ENVCONF_PORT": "http"}))
+
+    _, err := e.LookupInt("port")
+    assert.False(errors.Is(err, ErrNotSet))
+
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("ENVCONF_PORT", perr.Key)
+        assert.Equal("http", perr.Value)
+        assert.Equal("int", perr.Type)
+        assert.True(errors.Is(err, strconv.ErrSyntax))
+    }
+    assert.Contains(err.Error(), "Failed to parse int from environment variable \"ENVCONF_PORT\"")
+
+    _, err = e.LookupDuration("port")
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("time.Duration", perr.Type)
+    }
+
+    _, err = e.LookupBool("port")
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("bool", perr.Type)
+        assert.True(errors.Is(err, strconv.ErrSyntax))
+    }
+
+    e.SetString("port", "99999999999")
+    _, err = e.LookupInt("port")
+    assert.True(errors.Is(err, strconv.ErrRange))
+    _, err = e.LookupUInt("port")
+    assert.True(errors.Is(err, strconv.ErrRange))
+    _, err = e.LookupInt64("port")
+    assert.NoError(err)
+
+    // Get functions report failures as ok=false
+    _, ok := e.GetInt("port")
+    assert.False(ok)
+    _, ok = e.GetInt("missing")
+    assert.False(ok)
+}
+
+func TestProcessErrors(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        Port  int    `env:"port"`
+        Token string `env:"token" required:"true"`
+    }
+
+    e := New("envconf")
+    e.SetSource(NewMapSource(map[string]string{"ENVCONF_PORT": "http"}))
+
+    var perr *ParseError
+    err := e.Process(&cfg)
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("ENVCONF_PORT", perr.Key)
+        assert.Equal("int", perr.Type)
+    }
+
+    e.SetInt("port", 80)
+    assert.True(errors.Is(e.Process(&cfg), ErrNotSet))
+}
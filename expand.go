@@ -0,0 +1,251 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strings"
+)
+
+// ErrCycle is returned if variables reference each other during expansion.
+var ErrCycle = errors.New("reference cycle")
+
+// ExpandError is returned if a reference inside a value could not be expanded.
+type ExpandError struct {
+    Key string // key whose value contains the reference, empty for Env.Expand
+    Ref string // the reference, e.g. "${DB_HOST}"
+    Err error  // matches ErrNotSet or ErrCycle, or carries the ${VAR:?message}
+}
+
+func (e *ExpandError) Error() string {
+    msg := "Failed to expand " + e.Ref
+    if e.Key != "" {
+        msg += " in environment variable \"" + e.Key + "\""
+    }
+
+    return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ExpandError) Unwrap() error {
+    return e.Err
+}
+
+// SetExpand enables or disables the expansion of references in values
+// returned by the Lookup, Get and MustGet functions. See Expand for the
+// supported syntax.
+func (e *Env) SetExpand(enabled bool) {
+    e.expand = enabled
+}
+
+// Expand replaces references to environment variables in s.
+//
+// Supported are $VAR and ${VAR}, which fail if VAR is not set, and the
+// shell-style forms ${VAR:-default}, ${VAR-default}, ${VAR:?message},
+// ${VAR?message}, ${VAR:+alt} and ${VAR+alt}. The forms with colon treat an
+// empty variable like a missing one; ${VAR:+alt} and ${VAR+alt} only check
+// the value of VAR as it is, without expanding it. $$ yields a single $.
+//
+// Referenced names are normalized and prefixed like every other key of e;
+// if no such variable exists, the name is looked up unprefixed as well, so
+// that ${HOME} keeps working. Values of referenced variables are expanded
+// recursively, reference cycles are reported as ErrCycle.
+func (e *Env) Expand(s string) (string, error) {
+    return e.expandValue("", s, nil)
+}
+
+func isNameByte(c byte, first bool) bool {
+    return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || (!first && '0' <= c && c <= '9')
+}
+
+// expandValue expands s, the value of key. stack holds the keys currently
+// being expanded and is used to detect cycles.
+func (e *Env) expandValue(key, s string, stack []string) (string, error) {
+    if !strings.Contains(s, "$") {
+        return s, nil
+    }
+
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        if c != '$' || i+1 == len(s) {
+            b.WriteByte(c)
+            continue
+        }
+
+        switch n := s[i+1]; {
+        case n == '$':
+            b.WriteByte('$')
+            i++
+        case n == '{':
+            end := matchingBrace(s, i+1)
+            if end < 0 {
+                return "", &ExpandError{Key: key, Ref: s[i:], Err: errors.New("missing closing brace")}
+            }
+
+            v, err := e.expandParam(key, s[i:end+1], stack)
+            if err != nil {
+                return "", err
+            }
+            b.WriteString(v)
+            i = end
+        case isNameByte(n, true):
+            j := i + 1
+            for j < len(s) && isNameByte(s[j], false) {
+                j++
+            }
+
+            ref := s[i:j]
+            v, ok, err := e.resolve(ref[1:], stack)
+            if err != nil {
+                return "", wrapExpandError(key, ref, err)
+            }
+            if !ok {
+                return "", &ExpandError{Key: key, Ref: ref, Err: ErrNotSet}
+            }
+            b.WriteString(v)
+            i = j - 1
+        default:
+            b.WriteByte(c)
+        }
+    }
+
+    return b.String(), nil
+}
+
+// matchingBrace returns the index of the brace closing the one at s[open],
+// or -1.
+func matchingBrace(s string, open int) int {
+    depth := 0
+    for i := open; i < len(s); i++ {
+        switch s[i] {
+        case '{':
+            depth++
+        case '}':
+            depth--
+            if depth == 0 {
+                return i
+            }
+        }
+    }
+
+    return -1
+}
+
+// expandParam expands a single reference of the form ${...}.
+func (e *Env) expandParam(key, ref string, stack []string) (string, error) {
+    expr := ref[2 : len(ref)-1]
+
+    n := 0
+    for n < len(expr) && isNameByte(expr[n], n == 0) {
+        n++
+    }
+    name, op := expr[:n], expr[n:]
+    if name == "" {
+        return "", &ExpandError{Key: key, Ref: ref, Err: errors.New("invalid variable name")}
+    }
+
+    colon := strings.HasPrefix(op, ":")
+    if colon {
+        op = op[1:]
+    }
+    if op == "" && colon {
+        return "", &ExpandError{Key: key, Ref: ref, Err: errors.New("invalid expansion")}
+    }
+
+    // ${VAR+alt} does not use the value of VAR, so it is not expanded.
+    refKey, raw, ok := e.lookupRef(name)
+    if op != "" && op[0] == '+' {
+        if !ok || (colon && raw == "") {
+            return "", nil
+        }
+        return e.expandValue(key, op[1:], stack)
+    }
+
+    var v string
+    if ok {
+        var err error
+        if v, err = e.expandRef(refKey, raw, stack); err != nil {
+            return "", wrapExpandError(key, ref, err)
+        }
+    }
+    if colon {
+        ok = ok && v != ""
+    }
+    if op == "" {
+        if !ok {
+            return "", &ExpandError{Key: key, Ref: ref, Err: ErrNotSet}
+        }
+        return v, nil
+    }
+
+    word := op[1:]
+    switch op[0] {
+    case '-':
+        if ok {
+            return v, nil
+        }
+        return e.expandValue(key, word, stack)
+    case '?':
+        if ok {
+            return v, nil
+        }
+        msg, err := e.expandValue(key, word, stack)
+        if err != nil {
+            return "", err
+        }
+        if msg == "" {
+            msg = "not set"
+        }
+        return "", &ExpandError{Key: key, Ref: ref, Err: errors.New(msg)}
+    }
+
+    return "", &ExpandError{Key: key, Ref: ref, Err: errors.New("invalid expansion")}
+}
+
+// resolve looks up and expands the variable referenced by name.
+func (e *Env) resolve(name string, stack []string) (string, bool, error) {
+    key, v, ok := e.lookupRef(name)
+    if !ok {
+        return "", false, nil
+    }
+
+    v, err := e.expandRef(key, v, stack)
+    return v, err == nil, err
+}
+
+// lookupRef returns the key and the unexpanded value of the variable
+// referenced by name.
+func (e *Env) lookupRef(name string) (string, string, bool) {
+    key := e.prepareKey(name)
+    v, ok := e.source.Lookup(key)
+    if !ok && key != name {
+        key = name
+        v, ok = e.source.Lookup(key)
+    }
+
+    return key, v, ok
+}
+
+// expandRef expands v, the value of the referenced key.
+func (e *Env) expandRef(key, v string, stack []string) (string, error) {
+    for _, k := range stack {
+        if k == key {
+            return "", ErrCycle
+        }
+    }
+
+    return e.expandValue(key, v, append(stack[:len(stack):len(stack)], key))
+}
+
+// wrapExpandError wraps errors which are not yet an *ExpandError.
+func wrapExpandError(key, ref string, err error) error {
+    var eerr *ExpandError
+    if errors.As(err, &eerr) {
+        return err
+    }
+
+    return &ExpandError{Key: key, Ref: ref, Err: err}
+}
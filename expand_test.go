@@ -0,0 +1,131 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func newExpandTestEnv() *Env {
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_USER": "admin",
+        "APP_DB_HOST": "db.local",
+        "APP_DB_URL":  "postgres://${DB_USER}@$DB_HOST/app",
+        "APP_EMPTY":   "",
+        "APP_NESTED":  "${DB_URL}?sslmode=disable",
+        "APP_CYCLE_A": "${CYCLE_B}",
+        "APP_CYCLE_B": "x$CYCLE_A",
+        "APP_SELF":    "$SELF",
+        "APP_BROKEN":  "$MISSING",
+        "APP_PORT":    "${PORT_NUMBER:-8080}",
+        "HOME":        "/home/app",
+    }))
+    e.SetExpand(true)
+    return e
+}
+
+func TestExpand(t *testing.T) {
+    assert := assert.New(t)
+
+    e := newExpandTestEnv()
+
+    for in, out := range map[string]string{
+        "plain":                     "plain",
+        "$DB_USER":                  "admin",
+        "${DB_USER}":                "admin",
+        "${db_user}":                "admin",
+        "$DB_USER.$DB_HOST":         "admin.db.local",
+        "$$DB_USER":                 "$DB_USER",
+        "cost: 5$":                  "cost: 5$",
+        "$ 1":                       "$ 1",
+        "${HOME}/bin":               "/home/app/bin",
+        "${MISSING:-default}":       "default",
+        "${MISSING-default}":        "default",
+        "${EMPTY:-default}":         "default",
+        "${EMPTY-default}":          "",
+        "${MISSING:-${DB_USER}}":    "admin",
+        "${DB_USER:-default}":       "admin",
+        "${DB_USER:+alt}":           "alt",
+        "${DB_USER+alt}":            "alt",
+        "${EMPTY:+alt}":             "",
+        "${EMPTY+alt}":              "alt",
+        "${MISSING+alt}":            "",
+        "${CYCLE_A:+alt}":           "alt",
+        "${SELF+$DB_USER}":          "admin",
+        "${BROKEN:+alt}":            "alt",
+        "${DB_USER:?required}":      "admin",
+        "${EMPTY?required}":         "",
+        "${NESTED}":                 "postgres://admin@db.local/app?sslmode=disable",
+    } {
+        v, err := e.Expand(in)
+        assert.NoError(err, in)
+        assert.Equal(out, v, in)
+    }
+}
+
+func TestExpandErrors(t *testing.T) {
+    assert := assert.New(t)
+
+    e := newExpandTestEnv()
+
+    var eerr *ExpandError
+
+    _, err := e.Expand("$MISSING")
+    assert.True(errors.Is(err, ErrNotSet))
+    assert.Equal("Failed to expand $MISSING: environment variable not set", err.Error())
+
+    _, err = e.Expand("${MISSING}")
+    assert.True(errors.Is(err, ErrNotSet))
+
+    _, err = e.Expand("${MISSING:?database host required}")
+    if assert.True(errors.As(err, &eerr)) {
+        assert.Equal("${MISSING:?database host required}", eerr.Ref)
+        assert.Equal("database host required", eerr.Err.Error())
+    }
+
+    _, err = e.Expand("${EMPTY:?}")
+    assert.Error(err)
+
+    _, err = e.Expand("${DB_USER")
+    assert.Error(err)
+    _, err = e.Expand("${}")
+    assert.Error(err)
+    _, err = e.Expand("${DB_USER:x}")
+    assert.Error(err)
+    _, err = e.Expand("${DB_USER:}")
+    assert.Error(err)
+
+    _, err = e.LookupString("cycle a")
+    assert.True(errors.Is(err, ErrCycle))
+    if assert.True(errors.As(err, &eerr)) {
+        assert.Equal("APP_CYCLE_B", eerr.Key)
+        assert.Equal("$CYCLE_A", eerr.Ref)
+    }
+
+    _, err = e.LookupString("self")
+    assert.True(errors.Is(err, ErrCycle))
+}
+
+func TestExpandLookup(t *testing.T) {
+    assert := assert.New(t)
+
+    e := newExpandTestEnv()
+
+    assert.Equal("postgres://admin@db.local/app", e.MustGetString("db url"))
+    assert.Equal(8080, e.MustGetInt("port"))
+
+    _, ok := e.GetString("cycle a")
+    assert.False(ok)
+    assert.Panics(func() { e.MustGetString("cycle a") })
+
+    e.SetExpand(false)
+    assert.Equal("postgres://${DB_USER}@$DB_HOST/app", e.MustGetString("db url"))
+    _, ok = e.GetInt("port")
+    assert.False(ok)
+}
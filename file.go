@@ -0,0 +1,120 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// DefaultFileSizeLimit is the default maximum size of files read through the
+// _FILE convention.
+const DefaultFileSizeLimit = 1 << 20
+
+var (
+    // ErrFileConflict is returned if both KEY and KEY_FILE are set.
+    ErrFileConflict = errors.New("variable and its _FILE variant are both set")
+
+    // ErrFileTooLarge is returned if a file exceeds the size limit.
+    ErrFileTooLarge = errors.New("file exceeds size limit")
+)
+
+// FileError is returned if a value could not be read through the _FILE
+// convention.
+type FileError struct {
+    Key  string // normalized and prefixed key, without the _FILE suffix
+    Path string // path of the file, empty for ErrFileConflict
+    Err  error
+}
+
+func (e *FileError) Error() string {
+    msg := "Failed to read environment variable \"" + e.Key + "\""
+    if e.Path != "" {
+        msg += " from file " + strconv.Quote(e.Path)
+    }
+
+    return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FileError) Unwrap() error {
+    return e.Err
+}
+
+// SetFileFallback enables or disables the _FILE convention used by Docker
+// and Kubernetes secrets. If enabled and KEY is not set, but KEY_FILE is,
+// the value is read from the file named by KEY_FILE, with a single trailing
+// newline removed. Both variables being set is an error (ErrFileConflict).
+// KEY_FILE is derived from the normalized and prefixed key.
+func (e *Env) SetFileFallback(enabled bool) {
+    e.fileFallback = enabled
+}
+
+// SetFileSizeLimit sets the maximum size in bytes of files read through the
+// _FILE convention. Larger files are rejected with ErrFileTooLarge. A limit
+// less than or equal to zero restores DefaultFileSizeLimit.
+func (e *Env) SetFileSizeLimit(limit int64) {
+    if limit <= 0 {
+        limit = DefaultFileSizeLimit
+    }
+
+    e.fileLimit = limit
+}
+
+// lookupFile resolves the _FILE variant of the prepared key. ok reports
+// whether key was set directly.
+func (e *Env) lookupFile(key string, ok bool) (string, bool, error) {
+    path, fok := e.source.Lookup(key + "_FILE")
+    if !fok {
+        return "", false, nil
+    }
+    if ok {
+        return "", false, &FileError{Key: key, Err: ErrFileConflict}
+    }
+
+    v, err := readFileLimit(path, e.fileLimit)
+    if err != nil {
+        return "", false, &FileError{Key: key, Path: path, Err: err}
+    }
+
+    return v, true, nil
+}
+
+// readFileLimit reads the file at path if it is not larger than limit and
+// removes a single trailing newline.
+func readFileLimit(path string, limit int64) (string, error) {
+    s, err := readFileRaw(path, limit)
+    if err != nil {
+        return "", err
+    }
+
+    if strings.HasSuffix(s, "\r\n") {
+        return s[:len(s)-2], nil
+    }
+
+    return strings.TrimSuffix(s, "\n"), nil
+}
+
+// readFileRaw reads the file at path if it is not larger than limit.
+func readFileRaw(path string, limit int64) (string, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return "", err
+    }
+    defer f.Close()
+
+    b, err := io.ReadAll(io.LimitReader(f, limit+1))
+    if err != nil {
+        return "", err
+    }
+    if int64(len(b)) > limit {
+        return "", ErrFileTooLarge
+    }
+
+    return string(b), nil
+}
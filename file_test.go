@@ -0,0 +1,104 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func writeTestFile(t *testing.T, name, content string) string {
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+        t.Fatal(err)
+    }
+
+    return path
+}
+
+func TestFileFallback(t *testing.T) {
+    assert := assert.New(t)
+
+    password := writeTestFile(t, "db_password", "s3cr3t\n")
+    port := writeTestFile(t, "port", "5432\r\n")
+    multi := writeTestFile(t, "multi", "line1\nline2\n\n")
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_PASSWORD_FILE": password,
+        "APP_DB_PORT_FILE":     port,
+        "APP_MULTI_FILE":       multi,
+        "APP_MISSING_FILE":     filepath.Join(t.TempDir(), "missing"),
+    }))
+
+    _, err := e.LookupString("db password")
+    assert.True(errors.Is(err, ErrNotSet))
+    assert.False(e.IssetKey("db password"))
+
+    e.SetFileFallback(true)
+    assert.True(e.IssetKey("db password"))
+    assert.Equal("s3cr3t", e.MustGetString("db password"))
+    assert.Equal(5432, e.MustGetInt("db port"))
+    assert.Equal("line1\nline2\n", e.MustGetString("multi"))
+
+    _, err = e.LookupString("missing")
+    var ferr *FileError
+    if assert.True(errors.As(err, &ferr)) {
+        assert.Equal("APP_MISSING", ferr.Key)
+        assert.True(errors.Is(err, os.ErrNotExist))
+    }
+
+    e.SetString("db password", "other")
+    _, err = e.LookupString("db password")
+    assert.True(errors.Is(err, ErrFileConflict))
+    assert.Equal("Failed to read environment variable \"APP_DB_PASSWORD\": "+ErrFileConflict.Error(), err.Error())
+
+    e.SetDefaultString("db port", "1")
+    assert.Equal(5432, e.MustGetInt("db port"))
+
+    _, err = e.LookupString("unknown")
+    assert.True(errors.Is(err, ErrNotSet))
+}
+
+func TestFileSizeLimit(t *testing.T) {
+    assert := assert.New(t)
+
+    path := writeTestFile(t, "big", strings.Repeat("x", 64))
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_BIG_FILE": path}))
+    e.SetFileFallback(true)
+
+    assert.Len(e.MustGetString("big"), 64)
+
+    e.SetFileSizeLimit(63)
+    _, err := e.LookupString("big")
+    assert.True(errors.Is(err, ErrFileTooLarge))
+    assert.Contains(err.Error(), path)
+
+    e.SetFileSizeLimit(64)
+    assert.Len(e.MustGetString("big"), 64)
+
+    e.SetFileSizeLimit(0)
+    assert.Equal(int64(DefaultFileSizeLimit), e.fileLimit)
+}
+
+func TestFileFallbackExpand(t *testing.T) {
+    assert := assert.New(t)
+
+    path := writeTestFile(t, "token", "abc$def")
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_TOKEN_FILE": path}))
+    e.SetFileFallback(true)
+    e.SetExpand(true)
+
+    assert.Equal("abc$def", e.MustGetString("token"))
+}
@@ -0,0 +1,214 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "flag"
+    "reflect"
+    "strings"
+)
+
+// flagKey returns the normalized and prefixed key the flag name falls back
+// to, e.g. APP_DB_HOST for db-host and APP_MAX_CONNS for maxConns.
+func (e *Env) flagKey(name string) string {
+    return e.prepareKey(keyWords(name))
+}
+
+// flagName returns the name of the flag generated for the variable name,
+// e.g. db-host for "db host".
+func flagName(name string) string {
+    return strings.ToLower(strings.Join(strings.Fields(wordSeparators.Replace(name)), "-"))
+}
+
+// envUsage returns usage annotated with the environment variable key.
+func envUsage(usage, key string) string {
+    suffix := "(env " + key + ")"
+    switch {
+    case strings.HasSuffix(usage, suffix):
+        return usage
+    case usage == "":
+        return suffix
+    }
+
+    return usage + " " + suffix
+}
+
+// BindFlags appends the environment variable every flag of fs falls back to
+// to the usage message of the flag, so that -h shows it. Calling BindFlags
+// repeatedly does not annotate a flag twice.
+func (e *Env) BindFlags(fs *flag.FlagSet) {
+    fs.VisitAll(func(f *flag.Flag) {
+        f.Usage = envUsage(f.Usage, e.flagKey(f.Name))
+    })
+}
+
+// ParseFlags calls BindFlags and parses args with fs. Every flag which is
+// not passed in args is then set from its environment variable, e.g.
+// APP_DB_HOST for the flag db-host, if that is set. So a value is taken
+// from the command line first, from the environment second and the default
+// of the flag last. Errors of fs.Parse, including flag.ErrHelp, are
+// returned as they are; malformed environment variables and values which
+// violate the rules added with AddRules for the key of a flag are returned
+// as *ValidationError.
+func (e *Env) ParseFlags(fs *flag.FlagSet, args []string) error {
+    e.BindFlags(fs)
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    passed := make(map[string]bool)
+    fs.Visit(func(f *flag.Flag) {
+        passed[f.Name] = true
+    })
+
+    var problems []Problem
+    fs.VisitAll(func(f *flag.Flag) {
+        key := e.flagKey(f.Name)
+        problem := Problem{Key: key, Type: flagType(f), Example: e.redactExample(key, f.DefValue)}
+
+        if passed[f.Name] {
+            if err := e.checkFlagRules(f, key, f.Value.String()); err != nil {
+                problem.Err = err
+                problems = append(problems, problem)
+            }
+            return
+        }
+
+        str, err := e.lookup(keyWords(f.Name))
+        if errors.Is(err, ErrNotSet) {
+            return
+        }
+        if err != nil {
+            problem.Err = err
+            problems = append(problems, problem)
+            return
+        }
+
+        if err := fs.Set(f.Name, e.flagValue(f, str)); err != nil {
+            problem.Err = e.parseError(key, str, problem.Type, err)
+            problems = append(problems, problem)
+            return
+        }
+        if err := e.checkFlagRules(f, key, str); err != nil {
+            problem.Err = err
+            problems = append(problems, problem)
+        }
+    })
+
+    if len(problems) > 0 {
+        return &ValidationError{Problems: problems}
+    }
+
+    return nil
+}
+
+// checkFlagRules checks the value of f, which was set from str, against the
+// rules of the prepared key. Values of flags which do not implement
+// flag.Getter are checked as string.
+func (e *Env) checkFlagRules(f *flag.Flag, key, str string) error {
+    v := reflect.ValueOf(f.Value.String())
+    if g, ok := f.Value.(flag.Getter); ok && g.Get() != nil {
+        v = reflect.ValueOf(g.Get())
+    }
+
+    return e.checkRules(key, str, v, nil)
+}
+
+// flagType returns the name of the type of the value of f, if the value
+// implements flag.Getter, or "flag".
+func flagType(f *flag.Flag) string {
+    if g, ok := f.Value.(flag.Getter); ok && g.Get() != nil {
+        return reflect.TypeOf(g.Get()).String()
+    }
+
+    return "flag"
+}
+
+// flagValue converts the value str of an environment variable into the
+// syntax of the flag f, so that e.g. a bool flag accepts "yes". Values of
+// flags whose type e can not parse are returned as they are.
+func (e *Env) flagValue(f *flag.Flag, str string) string {
+    g, ok := f.Value.(flag.Getter)
+    if !ok || g.Get() == nil {
+        return str
+    }
+
+    v, err := e.parseValue(reflect.TypeOf(g.Get()), str)
+    if err != nil {
+        return str
+    }
+    formatted, err := e.formatValue(v)
+    if err != nil {
+        return str
+    }
+
+    return formatted
+}
+
+// varFlag is the flag.Value of a flag generated for a Variable.
+type varFlag struct {
+    set *VarSet
+    v   *Variable
+}
+
+func (f *varFlag) String() string {
+    if f.v == nil {
+        return ""
+    }
+
+    str, _ := f.set.env.formatValue(f.v.value)
+    if f.v.Secret && str != "" {
+        return Redacted
+    }
+
+    return str
+}
+
+func (f *varFlag) Set(str string) error {
+    value, err := f.set.env.parseValue(f.v.value.Type(), str)
+    if err != nil {
+        return err
+    }
+    if err := f.set.env.checkRules(f.v.Key, str, value, nil); err != nil {
+        return err
+    }
+
+    f.v.value.Set(value)
+    f.v.flagged = true
+    return nil
+}
+
+func (f *varFlag) Get() interface{} {
+    return f.v.value.Interface()
+}
+
+func (f *varFlag) IsBoolFlag() bool {
+    return f.v != nil && f.v.value.Kind() == reflect.Bool
+}
+
+// BindFlags defines a flag in fs for every variable registered in s. The
+// name of the flag is the lower-case name of the variable with words joined
+// by dashes, e.g. db-host for "db host", and its usage message names the
+// environment variable. A variable passed as flag is no longer read from the
+// environment by Parse.
+func (s *VarSet) BindFlags(fs *flag.FlagSet) {
+    for _, v := range s.Variables() {
+        fs.Var(&varFlag{set: s, v: v}, flagName(v.Name), envUsage(v.Usage, v.Key))
+    }
+}
+
+// ParseFlags calls BindFlags, parses args with fs and then resolves all
+// variables not passed in args with Parse. So a value is taken from the
+// command line first, from the environment second and the default of the
+// variable last.
+func (s *VarSet) ParseFlags(fs *flag.FlagSet, args []string) error {
+    s.BindFlags(fs)
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    return s.Parse()
+}
@@ -0,0 +1,148 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "errors"
+    "flag"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestParseFlags(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_HOST":   "db.example.com",
+        "APP_PORT":      "9090",
+        "APP_DEBUG":     "yes",
+        "APP_MAX_CONNS": "20",
+    }))
+
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    host := fs.String("db-host", "localhost", "database host")
+    port := fs.Int("port", 8080, "listen port")
+    debug := fs.Bool("debug", false, "")
+    name := fs.String("name", "app", "")
+    maxConns := fs.Int("maxConns", 10, "")
+
+    assert.NoError(e.ParseFlags(fs, []string{"-port", "7070"}))
+    assert.Equal("db.example.com", *host)
+    assert.Equal(20, *maxConns)
+    assert.Equal("(env APP_MAX_CONNS)", fs.Lookup("maxConns").Usage)
+    assert.Equal(7070, *port)
+    assert.True(*debug)
+    assert.Equal("app", *name)
+
+    assert.Equal("database host (env APP_DB_HOST)", fs.Lookup("db-host").Usage)
+    assert.Equal("(env APP_DEBUG)", fs.Lookup("debug").Usage)
+    e.BindFlags(fs)
+    assert.Equal("listen port (env APP_PORT)", fs.Lookup("port").Usage)
+
+    var usage bytes.Buffer
+    fs.SetOutput(&usage)
+    assert.Equal(flag.ErrHelp, e.ParseFlags(fs, []string{"-h"}))
+    assert.Contains(usage.String(), "(env APP_NAME)")
+
+    e.SetSource(NewMapSource(map[string]string{"APP_PORT": "http"}))
+    fs = flag.NewFlagSet("test", flag.ContinueOnError)
+    fs.Int("port", 8080, "")
+    err := e.ParseFlags(fs, nil)
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 1)
+    assert.Equal("APP_PORT", verr.Problems[0].Key)
+    assert.Equal("int", verr.Problems[0].Type)
+    assert.Equal("8080", verr.Problems[0].Example)
+    assert.NoError(e.ParseFlags(fs, []string{"-port", "1"}))
+}
+
+func TestParseFlagsSecretsAndRules(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_PASSWORD": "x",
+        "APP_WORKERS":     "100",
+    }))
+    e.AddRules("workers", Max(10))
+
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    fs.Int("db-password", 1234, "")
+    fs.Int("workers", 4, "")
+    fs.Int("retries", 3, "")
+    e.AddRules("retries", Min(1))
+
+    err := e.ParseFlags(fs, []string{"-retries", "0"})
+    var verr *ValidationError
+    if assert.True(errors.As(err, &verr)) && assert.Len(verr.Problems, 3) {
+        assert.Equal("APP_DB_PASSWORD", verr.Problems[0].Key)
+        assert.Equal(Redacted, verr.Problems[0].Example)
+        assert.NotContains(verr.Report(), "1234")
+
+        var rerr *RuleError
+        assert.Equal("APP_RETRIES", verr.Problems[1].Key)
+        assert.True(errors.As(verr.Problems[1].Err, &rerr))
+        assert.Equal("APP_WORKERS", verr.Problems[2].Key)
+        if assert.True(errors.As(verr.Problems[2].Err, &rerr)) {
+            assert.Equal("100", rerr.Value)
+        }
+    }
+
+    e.SetString("workers", "8")
+    e.UnsetKey("db password")
+    fs = flag.NewFlagSet("test", flag.ContinueOnError)
+    workers := fs.Int("workers", 4, "")
+    fs.Int("retries", 3, "")
+    assert.NoError(e.ParseFlags(fs, []string{"-retries", "2"}))
+    assert.Equal(8, *workers)
+}
+
+func TestVarSetParseFlags(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_HOST":  "db.example.com",
+        "APP_PORT":     "9090",
+        "APP_PASSWORD": "hunter2",
+    }))
+
+    s := NewVarSet(e)
+    host := s.String("db host", "localhost", "database host")
+    port := s.Int("port", 8080, "listen port")
+    debug := s.Bool("debug", false, "")
+    password := Define(s, "password", Secret("changeme"), "")
+    assert.NoError(s.MarkRequired("port"))
+
+    fs := flag.NewFlagSet("test", flag.ContinueOnError)
+    assert.NoError(s.ParseFlags(fs, []string{"-port", "7070", "-debug"}))
+    assert.Equal("db.example.com", *host)
+    assert.Equal(7070, *port)
+    assert.True(*debug)
+    assert.Equal(Secret("hunter2"), *password)
+
+    f := fs.Lookup("db-host")
+    assert.Equal("database host (env APP_DB_HOST)", f.Usage)
+    assert.Equal("localhost", f.DefValue)
+    assert.Equal(Redacted, fs.Lookup("password").Value.String())
+    assert.Equal(7070, fs.Lookup("port").Value.(flag.Getter).Get())
+
+    var usage bytes.Buffer
+    fs.SetOutput(&usage)
+    fs.PrintDefaults()
+    assert.Contains(usage.String(), "-db-host value")
+    assert.Contains(usage.String(), "(env APP_PORT)")
+    assert.NotContains(usage.String(), "changeme")
+
+    s = NewVarSet(e)
+    s.Int("port", 8080, "")
+    fs = flag.NewFlagSet("test", flag.ContinueOnError)
+    fs.SetOutput(&usage)
+    assert.Error(s.ParseFlags(fs, []string{"-port", "http"}))
+}
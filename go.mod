@@ -0,0 +1,5 @@
+module github.com/sboehmann/envconf
+
+go 1.21
+
+require github.com/stretchr/testify v1.9.0
@@ -0,0 +1,166 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+)
+
+// INIFileSource reads an INI file into a FileSource. See INISource for
+// details.
+func (e *Env) INIFileSource(path string) (*FileSource, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    return e.INISource(f, path)
+}
+
+// INISource reads an INI document from r into a FileSource whose keys are
+// those e looks up. The key host of the section [db] is stored under the key
+// e.prepareKey("db host"), e.g. APP_DB_HOST; dots in section names nest, so
+// [db.primary] maps to APP_DB_PRIMARY_*. Keys before the first section have
+// no section prefix.
+//
+// Keys and values are separated by '=' or ':'. Lines starting with ';' or
+// '#' are comments, as is the rest of an unquoted value after whitespace and
+// ';' or '#'. A line ending with a backslash is continued on the next line.
+// Values may be double-quoted, with the escape sequences of Go string
+// literals including \uXXXX, or single-quoted, which are taken literally.
+//
+// The name is used in error messages and origins; a malformed document is
+// reported as *SyntaxError with the line of the error.
+func (e *Env) INISource(r io.Reader, name string) (*FileSource, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    s := newFileSource(name)
+    errorf := func(line int, msg string) error {
+        return &SyntaxError{File: name, Line: line, Msg: msg}
+    }
+
+    var section string
+    lines := strings.Split(string(data), "\n")
+    for i := 0; i < len(lines); i++ {
+        line := i + 1
+        text := strings.TrimSpace(lines[i])
+        if text == "" || text[0] == ';' || text[0] == '#' {
+            continue
+        }
+
+        // Join continuation lines.
+        for strings.HasSuffix(text, "\\") && i+1 < len(lines) {
+            i++
+            text = text[:len(text)-1] + strings.TrimSpace(lines[i])
+        }
+
+        if text[0] == '[' {
+            end := strings.IndexByte(text, ']')
+            if end < 0 {
+                return nil, errorf(line, "missing ']' after section name")
+            }
+            if rest := strings.TrimSpace(text[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+                return nil, errorf(line, "unexpected character after section name")
+            }
+            section = strings.TrimSpace(text[1:end])
+            if section == "" {
+                return nil, errorf(line, "empty section name")
+            }
+            section = keyWords(section)
+            continue
+        }
+
+        sep := strings.IndexAny(text, "=:")
+        if sep < 0 {
+            return nil, errorf(line, "expected '=' or ':' after key "+strconv.Quote(text))
+        }
+        key := strings.TrimSpace(text[:sep])
+        if key == "" {
+            return nil, errorf(line, "missing key")
+        }
+
+        value, err := iniValue(strings.TrimSpace(text[sep+1:]))
+        if err != nil {
+            return nil, errorf(line, err.Error()+" in value of key "+strconv.Quote(key))
+        }
+
+        s.set(e.prepareKey(joinPrefix(section, keyWords(key))), value, line)
+    }
+
+    return s, nil
+}
+
+// iniValue unquotes value or strips an inline comment from it.
+func iniValue(value string) (string, error) {
+    switch {
+    case value == "":
+        return "", nil
+    case value[0] == '"':
+        end := closingQuote(value)
+        if end < 0 {
+            return "", errors.New("unterminated double-quoted value")
+        }
+        if err := checkComment(value[end+1:]); err != nil {
+            return "", err
+        }
+
+        unquoted, err := strconv.Unquote(value[:end+1])
+        if err != nil {
+            return "", errors.New("invalid escape sequence")
+        }
+        return unquoted, nil
+    case value[0] == '\'':
+        end := strings.IndexByte(value[1:], '\'')
+        if end < 0 {
+            return "", errors.New("unterminated single-quoted value")
+        }
+        if err := checkComment(value[end+2:]); err != nil {
+            return "", err
+        }
+        return value[1 : end+1], nil
+    }
+
+    for i := 1; i < len(value); i++ {
+        if (value[i] == ';' || value[i] == '#') && (value[i-1] == ' ' || value[i-1] == '\t') {
+            return strings.TrimSpace(value[:i]), nil
+        }
+    }
+
+    return value, nil
+}
+
+// closingQuote returns the index of the double quote closing the quoted
+// value s, or -1.
+func closingQuote(s string) int {
+    for i := 1; i < len(s); i++ {
+        switch s[i] {
+        case '\\':
+            i++
+        case '"':
+            return i
+        }
+    }
+
+    return -1
+}
+
+// checkComment reports an error if rest, which follows a quoted value,
+// contains more than blanks and a comment.
+func checkComment(rest string) error {
+    rest = strings.TrimSpace(rest)
+    if rest != "" && rest[0] != ';' && rest[0] != '#' {
+        return errors.New("unexpected character after quoted value")
+    }
+
+    return nil
+}
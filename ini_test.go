@@ -0,0 +1,83 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+const iniTestDocument = `; global settings
+name = demo
+timeout: 5s
+
+[db]
+host = db.example.com   ; inline comment
+maxConns=10
+password = "p#ss \"w\u00f6rd\""  # quoted
+raw = 'C:\path'
+
+[db.replica]
+hosts = a, \
+        b, \
+        c
+empty =
+`
+
+func TestINISource(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    src, err := e.INISource(strings.NewReader(iniTestDocument), "app.ini")
+    assert.NoError(err)
+    e.SetSource(src)
+
+    assert.Equal("demo", e.MustGetString("name"))
+    assert.Equal(5*time.Second, e.MustGetDuration("timeout"))
+    assert.Equal("db.example.com", e.MustGetString("db host"))
+    assert.Equal(10, e.MustGetInt("db max conns"))
+    assert.Equal("p#ss \"wörd\"", e.MustGetString("db password"))
+    assert.Equal(`C:\path`, e.MustGetString("db raw"))
+    assert.Equal([]string{"a", "b", "c"}, e.MustGetStringSlice("db replica hosts"))
+    assert.Equal("", e.MustGetString("db replica empty"))
+
+    origin, ok := src.Origin("APP_DB_REPLICA_HOSTS")
+    assert.True(ok)
+    assert.Equal(Origin{File: "app.ini", Line: 12}, origin)
+}
+
+func TestINISourceError(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("")
+    for _, tt := range []struct {
+        doc  string
+        line int
+    }{
+        {"a = 1\n[db\nb = 2", 2},
+        {"[]", 1},
+        {"[db] x", 1},
+        {"a = 1\n\nb", 3},
+        {"= 1", 1},
+        {"a = \"open", 1},
+        {"a = 'open", 1},
+        {"a = \"x\" y", 1},
+        {"a = \"\\q\"", 1},
+    } {
+        _, err := e.INISource(strings.NewReader(tt.doc), "app.ini")
+        var serr *SyntaxError
+        if assert.True(errors.As(err, &serr), tt.doc) {
+            assert.Equal("app.ini", serr.File)
+            assert.Equal(tt.line, serr.Line, tt.doc)
+        }
+    }
+
+    _, err := e.INIFileSource("testdata/missing.ini")
+    assert.Error(err)
+}
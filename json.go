@@ -0,0 +1,192 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "io"
+    "os"
+    "strconv"
+)
+
+// JSONFileSource reads a JSON file into a FileSource. See JSONSource for
+// details.
+func (e *Env) JSONFileSource(path string) (*FileSource, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    return e.JSONSource(f, path)
+}
+
+// JSONSource reads a JSON object from r into a FileSource whose keys are
+// those e looks up. Nested objects are flattened, so {"db": {"maxConns": 5}}
+// is stored under the key e.prepareKey("db max conns"), e.g.
+// APP_DB_MAX_CONNS. The elements of an array are stored under indexed keys,
+// e.g. APP_SERVERS_0_HOST, which Process binds to slices of structs. Arrays
+// which contain no objects or arrays are also stored as list under the key
+// of the array, e.g. APP_TAGS="a,b", using the ListOptions of e.
+//
+// Strings are stored as they are, numbers as written in the document and
+// booleans as true or false, so the typed getters parse them like any other
+// value. Null values are not stored. The name is used in error messages and
+// origins; a malformed document and keys which are equal after flattening,
+// like a.b and a_b, are reported as *SyntaxError.
+func (e *Env) JSONSource(r io.Reader, name string) (*FileSource, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    p := &jsonParser{env: e, dec: json.NewDecoder(bytes.NewReader(data)), data: data,
+        name: name, source: newFileSource(name)}
+    p.dec.UseNumber()
+
+    if err := p.parse(); err != nil {
+        return nil, err
+    }
+
+    return p.source, nil
+}
+
+// jsonParser flattens a JSON document token by token, which allows to
+// remember the line of every value.
+type jsonParser struct {
+    env    *Env
+    dec    *json.Decoder
+    data   []byte
+    name   string
+    source *FileSource
+}
+
+// line returns the line of the last token read.
+func (p *jsonParser) line() int {
+    return bytes.Count(p.data[:p.dec.InputOffset()], []byte("\n")) + 1
+}
+
+// syntaxError converts errors of the decoder into a *SyntaxError.
+func (p *jsonParser) syntaxError(err error) error {
+    var serr *json.SyntaxError
+    switch {
+    case errors.As(err, &serr):
+        line := bytes.Count(p.data[:serr.Offset], []byte("\n")) + 1
+        return &SyntaxError{File: p.name, Line: line, Msg: serr.Error()}
+    case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+        return &SyntaxError{File: p.name, Line: p.line(), Msg: "unexpected end of JSON input"}
+    }
+
+    return err
+}
+
+func (p *jsonParser) parse() error {
+    tok, err := p.dec.Token()
+    if err != nil {
+        return p.syntaxError(err)
+    }
+    if tok != json.Delim('{') {
+        return &SyntaxError{File: p.name, Line: p.line(), Msg: "expected JSON object"}
+    }
+    if err := p.parseObject(""); err != nil {
+        return err
+    }
+
+    if _, err := p.dec.Token(); err != io.EOF {
+        if err != nil {
+            return p.syntaxError(err)
+        }
+        return &SyntaxError{File: p.name, Line: p.line(), Msg: "unexpected data after JSON object"}
+    }
+
+    return nil
+}
+
+// parseObject reads the members of an object whose opening brace has been
+// read.
+func (p *jsonParser) parseObject(prefix string) error {
+    for p.dec.More() {
+        tok, err := p.dec.Token()
+        if err != nil {
+            return p.syntaxError(err)
+        }
+
+        name := keyWords(tok.(string))
+        if _, _, err := p.parseValue(joinPrefix(prefix, name)); err != nil {
+            return err
+        }
+    }
+
+    _, err := p.dec.Token()
+    return p.syntaxError(err)
+}
+
+// parseArray reads the elements of an array whose opening bracket has been
+// read and stores them as list if all of them are scalars.
+func (p *jsonParser) parseArray(prefix string, line int) error {
+    var elems []string
+    scalars := true
+    for i := 0; p.dec.More(); i++ {
+        str, scalar, err := p.parseValue(joinPrefix(prefix, strconv.Itoa(i)))
+        if err != nil {
+            return err
+        }
+        scalars = scalars && scalar
+        elems = append(elems, str)
+    }
+
+    if _, err := p.dec.Token(); err != nil {
+        return p.syntaxError(err)
+    }
+    if scalars {
+        return p.set(prefix, joinList(elems, p.env.list), line)
+    }
+
+    return nil
+}
+
+// parseValue reads a value and stores it under prefix. It returns the value
+// and whether it is a scalar, null values are not stored.
+func (p *jsonParser) parseValue(prefix string) (string, bool, error) {
+    tok, err := p.dec.Token()
+    if err != nil {
+        return "", false, p.syntaxError(err)
+    }
+
+    var str string
+    switch v := tok.(type) {
+    case json.Delim:
+        if v == '{' {
+            return "", false, p.parseObject(prefix)
+        }
+        return "", false, p.parseArray(prefix, p.line())
+    case nil:
+        return "", true, nil
+    case string:
+        str = v
+    case json.Number:
+        str = v.String()
+    case bool:
+        str = strconv.FormatBool(v)
+    }
+
+    return str, true, p.set(prefix, str, p.line())
+}
+
+// set stores value under the key of prefix. Keys which collide after
+// flattening, e.g. of {"a": {"b": 1}, "a_b": 2}, are reported as
+// *SyntaxError.
+func (p *jsonParser) set(prefix, value string, line int) error {
+    key := p.env.prepareKey(prefix)
+    if prev, ok := p.source.values[key]; ok {
+        return &SyntaxError{File: p.name, Line: line,
+            Msg: "key " + strconv.Quote(key) + " already set at line " + strconv.Itoa(prev.line)}
+    }
+
+    p.source.set(key, value, line)
+    return nil
+}
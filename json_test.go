@@ -0,0 +1,103 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+const jsonTestDocument = `{
+    "name": "demo",
+    "port": 8080,
+    "ratio": 0.25,
+    "debug": true,
+    "timeout": "5s",
+    "optional": null,
+    "db": {
+        "host": "db.example.com",
+        "maxConns": 10
+    },
+    "tags": ["a", "b,c", 3],
+    "upstreams": [
+        {"host": "a", "port": 80},
+        {"host": "b", "port": 81}
+    ]
+}`
+
+func TestJSONSource(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    src, err := e.JSONSource(strings.NewReader(jsonTestDocument), "config.json")
+    assert.NoError(err)
+    e.SetSource(src)
+
+    assert.Equal("demo", e.MustGetString("name"))
+    assert.Equal(8080, e.MustGetInt("port"))
+    assert.Equal(0.25, e.MustGetFloat64("ratio"))
+    assert.True(e.MustGetBool("debug"))
+    assert.Equal(5*time.Second, e.MustGetDuration("timeout"))
+    assert.False(e.IssetKey("optional"))
+    assert.Equal("db.example.com", e.MustGetString("db host"))
+    assert.Equal(10, e.MustGetInt("db max conns"))
+    assert.Equal([]string{"a", "b,c", "3"}, e.MustGetStringSlice("tags"))
+    assert.Equal("b,c", e.MustGetString("tags 1"))
+    assert.Equal("b", e.MustGetString("upstreams 1 host"))
+    assert.False(e.IssetKey("upstreams"))
+
+    origin, ok := src.Origin("APP_DB_MAX_CONNS")
+    assert.True(ok)
+    assert.Equal(Origin{File: "config.json", Line: 10}, origin)
+    origin, _ = src.Origin("APP_TAGS")
+    assert.Equal(12, origin.Line)
+
+    var c struct {
+        Port      int
+        Timeout   time.Duration
+        Tags      []string
+        Upstreams []collectionTestUpstream
+    }
+    assert.NoError(e.Process(&c))
+    assert.Equal(8080, c.Port)
+    assert.Len(c.Upstreams, 2)
+    assert.Equal(81, c.Upstreams[1].Port)
+}
+
+func TestJSONSourceError(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("")
+    for _, tt := range []struct {
+        doc  string
+        line int
+    }{
+        {"[1, 2]", 1},
+        {"{\n  \"a\": 1,\n  \"b\": }", 3},
+        {"{\n  \"a\": [1,\n", 3},
+        {"{} {}", 1},
+        {"", 1},
+    } {
+        _, err := e.JSONSource(strings.NewReader(tt.doc), "config.json")
+        var serr *SyntaxError
+        if assert.True(errors.As(err, &serr), tt.doc) {
+            assert.Equal("config.json", serr.File)
+            assert.Equal(tt.line, serr.Line, tt.doc)
+        }
+    }
+
+    _, err := e.JSONSource(strings.NewReader("{\n  \"a\": {\"b\": 1},\n  \"a_b\": 2\n}"), "config.json")
+    var serr *SyntaxError
+    if assert.True(errors.As(err, &serr)) {
+        assert.Equal("config.json:3: key \"A_B\" already set at line 2", serr.Error())
+    }
+
+    _, err = e.JSONFileSource("testdata/missing.json")
+    assert.Error(err)
+}
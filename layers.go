@@ -0,0 +1,304 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+    "sync"
+)
+
+// Origin describes where a value comes from. Unknown fields are empty.
+type Origin struct {
+    Layer string // name of the layer of Layers
+    File  string // name of the file the value was read from
+    Line  int    // line in File, 0 if unknown
+}
+
+func (o Origin) String() string {
+    file := o.File
+    if file != "" && o.Line > 0 {
+        file += ":" + strconv.Itoa(o.Line)
+    }
+
+    switch {
+    case o.Layer == "":
+        return file
+    case file == "":
+        return o.Layer
+    }
+
+    return o.Layer + " (" + file + ")"
+}
+
+// Originator is implemented by sources which know where their values come
+// from, like FileSource and Layers.
+type Originator interface {
+    Origin(key string) (Origin, bool)
+}
+
+// Candidate is a value of a key found in one layer.
+type Candidate struct {
+    Value  string
+    Origin Origin
+}
+
+// Explanation describes how the value of a key was resolved.
+type Explanation struct {
+    Key      string
+    Found    bool        // whether any layer contains the key
+    Value    string      // the resolved value
+    Origin   Origin      // where the resolved value comes from
+    Shadowed []Candidate // values of layers with lower precedence, highest first
+}
+
+func (x Explanation) String() string {
+    if !x.Found {
+        return x.Key + " is not set"
+    }
+
+    s := x.Key + "=" + strconv.Quote(x.Value) + " from " + x.Origin.String()
+    for _, c := range x.Shadowed {
+        s += "\n    shadows " + strconv.Quote(c.Value) + " from " + c.Origin.String()
+    }
+
+    return s
+}
+
+// Layers is a Source stacking named sources by precedence, e.g. defaults <
+// config file < .env file < process environment < overrides. A key is
+// looked up from the layer with the highest precedence down and the first
+// value found wins. Layers is safe for concurrent use if its layers are.
+type Layers struct {
+    mu     sync.RWMutex
+    layers []layer // lowest precedence first
+}
+
+type layer struct {
+    name   string
+    source Source
+}
+
+// NewLayers returns an empty Layers.
+func NewLayers() *Layers {
+    return &Layers{}
+}
+
+// Add adds the layer name with a higher precedence than all existing layers.
+// If a layer with the same name exists, its source is replaced in place and
+// its precedence is kept, which allows reloading a file.
+func (l *Layers) Add(name string, s Source) *Layers {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if i := l.index(name); i >= 0 {
+        l.layers[i].source = s
+        return l
+    }
+
+    l.layers = append(l.layers, layer{name: name, source: s})
+    return l
+}
+
+// Remove removes the layer name, if it exists.
+func (l *Layers) Remove(name string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if i := l.index(name); i >= 0 {
+        l.layers = append(l.layers[:i], l.layers[i+1:]...)
+    }
+}
+
+// Names returns the names of all layers, lowest precedence first.
+func (l *Layers) Names() []string {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    names := make([]string, len(l.layers))
+    for i, layer := range l.layers {
+        names[i] = layer.name
+    }
+
+    return names
+}
+
+// SetOrder changes the precedence of the layers. The names of all layers
+// must be given exactly once, lowest precedence first.
+func (l *Layers) SetOrder(names ...string) error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if len(names) != len(l.layers) {
+        return errors.New("SetOrder requires the names of all " + strconv.Itoa(len(l.layers)) + " layers")
+    }
+
+    layers := make([]layer, 0, len(names))
+    for _, name := range names {
+        i := l.index(name)
+        if i < 0 {
+            return errors.New("unknown layer " + strconv.Quote(name))
+        }
+        for _, layer := range layers {
+            if layer.name == name {
+                return errors.New("duplicate layer " + strconv.Quote(name))
+            }
+        }
+        layers = append(layers, l.layers[i])
+    }
+
+    l.layers = layers
+    return nil
+}
+
+// index returns the index of the layer name or -1, l.mu must be held.
+func (l *Layers) index(name string) int {
+    for i, layer := range l.layers {
+        if layer.name == name {
+            return i
+        }
+    }
+
+    return -1
+}
+
+// Lookup returns the value of key from the layer with the highest
+// precedence containing it.
+func (l *Layers) Lookup(key string) (string, bool) {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    for i := len(l.layers) - 1; i >= 0; i-- {
+        if v, ok := l.layers[i].source.Lookup(key); ok {
+            return v, true
+        }
+    }
+
+    return "", false
+}
+
+// Set stores value in the layer with the highest precedence implementing
+// Setter.
+func (l *Layers) Set(key, value string) error {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    for i := len(l.layers) - 1; i >= 0; i-- {
+        if s, ok := l.layers[i].source.(Setter); ok {
+            return s.Set(key, value)
+        }
+    }
+
+    return errors.New("no layer supports setting environment variables")
+}
+
+// Unset removes key from all layers implementing Unsetter.
+func (l *Layers) Unset(key string) error {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    for _, layer := range l.layers {
+        if s, ok := layer.source.(Unsetter); ok {
+            if err := s.Unset(key); err != nil {
+                return err
+            }
+        }
+    }
+
+    return nil
+}
+
+// Keys returns the sorted union of the keys of all layers implementing
+// Lister.
+func (l *Layers) Keys() []string {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    seen := make(map[string]bool)
+    var keys []string
+    for _, layer := range l.layers {
+        if lister, ok := layer.source.(Lister); ok {
+            for _, key := range lister.Keys() {
+                if !seen[key] {
+                    seen[key] = true
+                    keys = append(keys, key)
+                }
+            }
+        }
+    }
+
+    sort.Strings(keys)
+    return keys
+}
+
+// Origin returns the origin of the value Lookup returns for key.
+func (l *Layers) Origin(key string) (Origin, bool) {
+    x := l.Explain(key)
+    return x.Origin, x.Found
+}
+
+// Explain returns the value of key, the layer it comes from and the values
+// of all layers with lower precedence it shadows. File and line are filled
+// in for layers implementing Originator.
+func (l *Layers) Explain(key string) Explanation {
+    l.mu.RLock()
+    defer l.mu.RUnlock()
+
+    x := Explanation{Key: key}
+    for i := len(l.layers) - 1; i >= 0; i-- {
+        v, ok := l.layers[i].source.Lookup(key)
+        if !ok {
+            continue
+        }
+
+        c := Candidate{Value: v, Origin: Origin{Layer: l.layers[i].name}}
+        if o, ok := l.layers[i].source.(Originator); ok {
+            if origin, ok := o.Origin(key); ok {
+                c.Origin.File, c.Origin.Line = origin.File, origin.Line
+            }
+        }
+
+        if !x.Found {
+            x.Found, x.Value, x.Origin = true, c.Value, c.Origin
+        } else {
+            x.Shadowed = append(x.Shadowed, c)
+        }
+    }
+
+    return x
+}
+
+// Explain describes how the value of key is resolved by the Source of e. If
+// the Source is a Layers, the winning layer and all shadowed values are
+// returned. The values are the raw ones of the Source, before expansion and
+// the _FILE convention, with secret values redacted. The error matches
+// ErrNotSet if the key is not set in any layer.
+func (e *Env) Explain(key string) (Explanation, error) {
+    key = e.prepareKey(key)
+
+    var x Explanation
+    if l, ok := e.source.(*Layers); ok {
+        x = l.Explain(key)
+    } else {
+        x.Key = key
+        x.Value, x.Found = e.source.Lookup(key)
+        if o, ok := e.source.(Originator); ok && x.Found {
+            x.Origin, _ = o.Origin(key)
+        }
+    }
+
+    if x.Found && e.isSecretKey(key) {
+        x.Value = Redacted
+        for i := range x.Shadowed {
+            x.Shadowed[i].Value = Redacted
+        }
+    }
+    if !x.Found {
+        return x, &notSetError{key}
+    }
+
+    return x, nil
+}
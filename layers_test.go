@@ -0,0 +1,138 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func newTestLayers(t *testing.T) (*Layers, *MapSource) {
+    file, err := DotenvSource(strings.NewReader("APP_PORT=8081\nAPP_HOST=file.example.com\n\nAPP_DEBUG=true\n"), "config.env")
+    assert.NoError(t, err)
+
+    env := NewMapSource(map[string]string{"APP_PORT": "9090", "APP_PASSWORD": "hunter2"})
+    overrides := NewMapSource(nil)
+
+    l := NewLayers().
+        Add("defaults", NewMapSource(map[string]string{"APP_PORT": "8080", "APP_HOST": "localhost", "APP_PASSWORD": "changeme"})).
+        Add("file", file).
+        Add("env", env).
+        Add("overrides", overrides)
+
+    return l, overrides
+}
+
+func TestLayers(t *testing.T) {
+    assert := assert.New(t)
+
+    l, overrides := newTestLayers(t)
+    assert.Equal([]string{"defaults", "file", "env", "overrides"}, l.Names())
+
+    v, ok := l.Lookup("APP_PORT")
+    assert.True(ok)
+    assert.Equal("9090", v)
+    v, _ = l.Lookup("APP_HOST")
+    assert.Equal("file.example.com", v)
+    _, ok = l.Lookup("APP_MISSING")
+    assert.False(ok)
+    assert.Equal([]string{"APP_DEBUG", "APP_HOST", "APP_PASSWORD", "APP_PORT"}, l.Keys())
+
+    assert.NoError(l.Set("APP_PORT", "7070"))
+    v, _ = overrides.Lookup("APP_PORT")
+    assert.Equal("7070", v)
+    v, _ = l.Lookup("APP_PORT")
+    assert.Equal("7070", v)
+
+    assert.NoError(l.Unset("APP_PORT"))
+    v, _ = l.Lookup("APP_PORT")
+    assert.Equal("8081", v)
+
+    assert.NoError(l.SetOrder("defaults", "env", "overrides", "file"))
+    v, _ = l.Lookup("APP_HOST")
+    assert.Equal("file.example.com", v)
+    assert.Error(l.SetOrder("defaults", "env"))
+    assert.Error(l.SetOrder("defaults", "env", "env", "file"))
+    assert.Error(l.SetOrder("defaults", "env", "missing", "file"))
+    assert.Equal([]string{"defaults", "env", "overrides", "file"}, l.Names())
+
+    l.Remove("file")
+    l.Remove("missing")
+    v, _ = l.Lookup("APP_HOST")
+    assert.Equal("localhost", v)
+
+    l.Add("defaults", NewMapSource(map[string]string{"APP_HOST": "reloaded"}))
+    assert.Equal([]string{"defaults", "env", "overrides"}, l.Names())
+    v, _ = l.Lookup("APP_HOST")
+    assert.Equal("reloaded", v)
+
+    assert.Error(NewLayers().Add("file", LookupFunc(func(string) (string, bool) { return "", false })).Set("A", "B"))
+}
+
+func TestLayersExplain(t *testing.T) {
+    assert := assert.New(t)
+
+    l, _ := newTestLayers(t)
+
+    x := l.Explain("APP_HOST")
+    assert.Equal(Explanation{
+        Key:      "APP_HOST",
+        Found:    true,
+        Value:    "file.example.com",
+        Origin:   Origin{Layer: "file", File: "config.env", Line: 2},
+        Shadowed: []Candidate{{Value: "localhost", Origin: Origin{Layer: "defaults"}}},
+    }, x)
+    assert.Equal("APP_HOST=\"file.example.com\" from file (config.env:2)\n    shadows \"localhost\" from defaults", x.String())
+
+    origin, ok := l.Origin("APP_DEBUG")
+    assert.True(ok)
+    assert.Equal(Origin{Layer: "file", File: "config.env", Line: 4}, origin)
+
+    x = l.Explain("APP_MISSING")
+    assert.False(x.Found)
+    assert.Equal("APP_MISSING is not set", x.String())
+
+    assert.Equal("config.env", Origin{File: "config.env"}.String())
+    assert.Equal("", Origin{}.String())
+}
+
+func TestEnvExplain(t *testing.T) {
+    assert := assert.New(t)
+
+    l, _ := newTestLayers(t)
+    e := New("app")
+    e.SetSource(l)
+
+    x, err := e.Explain("port")
+    assert.NoError(err)
+    assert.Equal("9090", x.Value)
+    assert.Equal(Origin{Layer: "env"}, x.Origin)
+    assert.Len(x.Shadowed, 2)
+
+    x, err = e.Explain("password")
+    assert.NoError(err)
+    assert.Equal(Redacted, x.Value)
+    assert.Equal(Redacted, x.Shadowed[0].Value)
+    assert.NotContains(x.String(), "hunter2")
+
+    _, err = e.Explain("missing")
+    assert.True(errors.Is(err, ErrNotSet))
+
+    file, err := DotenvSource(strings.NewReader("APP_PORT=1\n"), "app.env")
+    assert.NoError(err)
+    e.SetSource(file)
+    x, err = e.Explain("port")
+    assert.NoError(err)
+    assert.Equal(Origin{File: "app.env", Line: 1}, x.Origin)
+    assert.Empty(x.Shadowed)
+
+    e.SetSource(NewMapSource(map[string]string{"APP_PORT": "1"}))
+    x, err = e.Explain("port")
+    assert.NoError(err)
+    assert.Equal("APP_PORT=\"1\" from ", x.String())
+}
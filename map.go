@@ -0,0 +1,307 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// splitMap splits str into key/value pairs. Keys must be unique. Each entry
+// is split at the first KeyValueSeparator outside of quotes, so keys
+// containing the separator must be quoted; key and value are unquoted
+// separately. An empty key must be quoted as well, e.g. ""=x.
+func splitMap(str string, o ListOptions) (map[string]string, error) {
+    if str == "" {
+        return map[string]string{}, nil
+    }
+
+    raws, err := splitUnquoted(str, o.Separator, -1)
+    if err != nil {
+        return nil, err
+    }
+
+    m := make(map[string]string, len(raws))
+    for _, raw := range raws {
+        elem, quoted := unquoteElem(raw, o.TrimSpace)
+        if elem == "" && !quoted && o.DropEmpty {
+            continue
+        }
+
+        kv, _ := splitUnquoted(raw, o.KeyValueSeparator, 2)
+        if len(kv) < 2 {
+            return nil, errors.New("missing " + strconv.Quote(o.KeyValueSeparator) + " in entry " + strconv.Quote(elem))
+        }
+
+        k, quoted := unquoteElem(kv[0], o.TrimSpace)
+        v, _ := unquoteElem(kv[1], o.TrimSpace)
+        if k == "" && !quoted {
+            return nil, errors.New("empty key in entry " + strconv.Quote(elem))
+        }
+        if _, ok := m[k]; ok {
+            return nil, errors.New("duplicate key " + strconv.Quote(k))
+        }
+
+        m[k] = v
+    }
+
+    return m, nil
+}
+
+// joinMap joins the entries of m sorted by key, so that splitMap returns
+// them again. Keys and values are quoted separately.
+func joinMap(m map[string]string, o ListOptions) string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    elems := make([]string, len(keys))
+    for i, k := range keys {
+        v := m[k]
+        if v != "" {
+            v = quoteElem(v, o, o.Separator)
+        }
+        elems[i] = quoteElem(k, o, o.Separator, o.KeyValueSeparator) + o.KeyValueSeparator + v
+    }
+
+    return strings.Join(elems, o.Separator)
+}
+
+// SetDefaultStringMap sets the environment if it is not already set.
+func (e *Env) SetDefaultStringMap(key string, values map[string]string) {
+    DefaultIn(e, key, values)
+}
+
+// SetStringMap sets the environment to the entries of values sorted by key.
+func (e *Env) SetStringMap(key string, values map[string]string) {
+    SetIn(e, key, values)
+}
+
+// LookupStringMap returns the environment parsed as map of string.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupStringMap(key string) (values map[string]string, err error) {
+    return GetFrom[map[string]string](e, key)
+}
+
+// GetStringMap returns the environment parsed as map of string.
+func (e *Env) GetStringMap(key string) (values map[string]string, ok bool) {
+    values, err := e.LookupStringMap(key)
+    return values, logError(err)
+}
+
+// MustGetStringMap returns the environment variable parsed as map of
+// string if possible, otherwise it panics.
+func (e *Env) MustGetStringMap(key string) (values map[string]string) {
+    return MustGetFrom[map[string]string](e, key)
+}
+
+// SetDefaultBoolMap sets the environment if it is not already set.
+func (e *Env) SetDefaultBoolMap(key string, values map[string]bool) {
+    DefaultIn(e, key, values)
+}
+
+// SetBoolMap sets the environment to the entries of values sorted by key.
+func (e *Env) SetBoolMap(key string, values map[string]bool) {
+    SetIn(e, key, values)
+}
+
+// LookupBoolMap returns the environment parsed as map of bool.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupBoolMap(key string) (values map[string]bool, err error) {
+    return GetFrom[map[string]bool](e, key)
+}
+
+// GetBoolMap returns the environment parsed as map of bool.
+func (e *Env) GetBoolMap(key string) (values map[string]bool, ok bool) {
+    values, err := e.LookupBoolMap(key)
+    return values, logError(err)
+}
+
+// MustGetBoolMap returns the environment variable parsed as map of
+// bool if possible, otherwise it panics.
+func (e *Env) MustGetBoolMap(key string) (values map[string]bool) {
+    return MustGetFrom[map[string]bool](e, key)
+}
+
+// SetDefaultDurationMap sets the environment if it is not already set.
+func (e *Env) SetDefaultDurationMap(key string, values map[string]time.Duration) {
+    DefaultIn(e, key, values)
+}
+
+// SetDurationMap sets the environment to the entries of values sorted by key.
+func (e *Env) SetDurationMap(key string, values map[string]time.Duration) {
+    SetIn(e, key, values)
+}
+
+// LookupDurationMap returns the environment parsed as map of time.Duration.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupDurationMap(key string) (values map[string]time.Duration, err error) {
+    return GetFrom[map[string]time.Duration](e, key)
+}
+
+// GetDurationMap returns the environment parsed as map of time.Duration.
+func (e *Env) GetDurationMap(key string) (values map[string]time.Duration, ok bool) {
+    values, err := e.LookupDurationMap(key)
+    return values, logError(err)
+}
+
+// MustGetDurationMap returns the environment variable parsed as map of
+// time.Duration if possible, otherwise it panics.
+func (e *Env) MustGetDurationMap(key string) (values map[string]time.Duration) {
+    return MustGetFrom[map[string]time.Duration](e, key)
+}
+
+// SetDefaultFloat64Map sets the environment if it is not already set.
+func (e *Env) SetDefaultFloat64Map(key string, values map[string]float64) {
+    DefaultIn(e, key, values)
+}
+
+// SetFloat64Map sets the environment to the entries of values sorted by key.
+func (e *Env) SetFloat64Map(key string, values map[string]float64) {
+    SetIn(e, key, values)
+}
+
+// LookupFloat64Map returns the environment parsed as map of float64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupFloat64Map(key string) (values map[string]float64, err error) {
+    return GetFrom[map[string]float64](e, key)
+}
+
+// GetFloat64Map returns the environment parsed as map of float64.
+func (e *Env) GetFloat64Map(key string) (values map[string]float64, ok bool) {
+    values, err := e.LookupFloat64Map(key)
+    return values, logError(err)
+}
+
+// MustGetFloat64Map returns the environment variable parsed as map of
+// float64 if possible, otherwise it panics.
+func (e *Env) MustGetFloat64Map(key string) (values map[string]float64) {
+    return MustGetFrom[map[string]float64](e, key)
+}
+
+// SetDefaultIntMap sets the environment if it is not already set.
+func (e *Env) SetDefaultIntMap(key string, values map[string]int) {
+    DefaultIn(e, key, values)
+}
+
+// SetIntMap sets the environment to the entries of values sorted by key.
+func (e *Env) SetIntMap(key string, values map[string]int) {
+    SetIn(e, key, values)
+}
+
+// LookupIntMap returns the environment parsed as map of int.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupIntMap(key string) (values map[string]int, err error) {
+    return GetFrom[map[string]int](e, key)
+}
+
+// GetIntMap returns the environment parsed as map of int.
+func (e *Env) GetIntMap(key string) (values map[string]int, ok bool) {
+    values, err := e.LookupIntMap(key)
+    return values, logError(err)
+}
+
+// MustGetIntMap returns the environment variable parsed as map of
+// int if possible, otherwise it panics.
+func (e *Env) MustGetIntMap(key string) (values map[string]int) {
+    return MustGetFrom[map[string]int](e, key)
+}
+
+// SetDefaultInt64Map sets the environment if it is not already set.
+func (e *Env) SetDefaultInt64Map(key string, values map[string]int64) {
+    DefaultIn(e, key, values)
+}
+
+// SetInt64Map sets the environment to the entries of values sorted by key.
+func (e *Env) SetInt64Map(key string, values map[string]int64) {
+    SetIn(e, key, values)
+}
+
+// LookupInt64Map returns the environment parsed as map of int64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupInt64Map(key string) (values map[string]int64, err error) {
+    return GetFrom[map[string]int64](e, key)
+}
+
+// GetInt64Map returns the environment parsed as map of int64.
+func (e *Env) GetInt64Map(key string) (values map[string]int64, ok bool) {
+    values, err := e.LookupInt64Map(key)
+    return values, logError(err)
+}
+
+// MustGetInt64Map returns the environment variable parsed as map of
+// int64 if possible, otherwise it panics.
+func (e *Env) MustGetInt64Map(key string) (values map[string]int64) {
+    return MustGetFrom[map[string]int64](e, key)
+}
+
+// SetDefaultUIntMap sets the environment if it is not already set.
+func (e *Env) SetDefaultUIntMap(key string, values map[string]uint) {
+    DefaultIn(e, key, values)
+}
+
+// SetUIntMap sets the environment to the entries of values sorted by key.
+func (e *Env) SetUIntMap(key string, values map[string]uint) {
+    SetIn(e, key, values)
+}
+
+// LookupUIntMap returns the environment parsed as map of uint.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupUIntMap(key string) (values map[string]uint, err error) {
+    return GetFrom[map[string]uint](e, key)
+}
+
+// GetUIntMap returns the environment parsed as map of uint.
+func (e *Env) GetUIntMap(key string) (values map[string]uint, ok bool) {
+    values, err := e.LookupUIntMap(key)
+    return values, logError(err)
+}
+
+// MustGetUIntMap returns the environment variable parsed as map of
+// uint if possible, otherwise it panics.
+func (e *Env) MustGetUIntMap(key string) (values map[string]uint) {
+    return MustGetFrom[map[string]uint](e, key)
+}
+
+// SetDefaultUInt64Map sets the environment if it is not already set.
+func (e *Env) SetDefaultUInt64Map(key string, values map[string]uint64) {
+    DefaultIn(e, key, values)
+}
+
+// SetUInt64Map sets the environment to the entries of values sorted by key.
+func (e *Env) SetUInt64Map(key string, values map[string]uint64) {
+    SetIn(e, key, values)
+}
+
+// LookupUInt64Map returns the environment parsed as map of uint64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupUInt64Map(key string) (values map[string]uint64, err error) {
+    return GetFrom[map[string]uint64](e, key)
+}
+
+// GetUInt64Map returns the environment parsed as map of uint64.
+func (e *Env) GetUInt64Map(key string) (values map[string]uint64, ok bool) {
+    values, err := e.LookupUInt64Map(key)
+    return values, logError(err)
+}
+
+// MustGetUInt64Map returns the environment variable parsed as map of
+// uint64 if possible, otherwise it panics.
+func (e *Env) MustGetUInt64Map(key string) (values map[string]uint64) {
+    return MustGetFrom[map[string]uint64](e, key)
+}
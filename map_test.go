@@ -0,0 +1,136 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSplitMap(t *testing.T) {
+    assert := assert.New(t)
+
+    o := defaultListOptions
+    m, err := splitMap("a=10, b = 20,c=x=y,\"d,e\"=1", o)
+    assert.NoError(err)
+    assert.Equal(map[string]string{"a": "10", "b": "20", "c": "x=y", "d,e": "1"}, m)
+
+    m, err = splitMap(`""=1,a=`, o)
+    assert.NoError(err)
+    assert.Equal(map[string]string{"": "1", "a": ""}, m)
+
+    m, err = splitMap("", o)
+    assert.NoError(err)
+    assert.Empty(m)
+
+    for _, in := range []string{"a", "a=1,b", "=1", "a=1,a=2", "\"a=1"} {
+        _, err = splitMap(in, o)
+        assert.Error(err, in)
+    }
+
+    o.KeyValueSeparator = ":"
+    m, err = splitMap("team:core,tier:1", o)
+    assert.NoError(err)
+    assert.Equal(map[string]string{"team": "core", "tier": "1"}, m)
+}
+
+func TestJoinMap(t *testing.T) {
+    assert := assert.New(t)
+
+    o := defaultListOptions
+    m := map[string]string{"b": "2", "a": "1", "c": "x,y", "d": ""}
+    str := joinMap(m, o)
+    assert.Equal("a=1,b=2,c=\"x,y\",d=", str)
+
+    out, err := splitMap(str, o)
+    assert.NoError(err)
+    assert.Equal(m, out)
+
+    m = map[string]string{"a": " x ", "b=c": "1", "d": "\"e\"=f", "": "g"}
+    str = joinMap(m, o)
+    assert.Equal(`""=g,a=" x ","b=c"=1,d="\"e\"=f"`, str)
+    out, err = splitMap(str, o)
+    assert.NoError(err)
+    assert.Equal(m, out)
+
+    assert.Equal("", joinMap(nil, o))
+}
+
+func TestMaps(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    _, err := e.LookupStringMap("map")
+    assert.True(errors.Is(err, ErrNotSet))
+    _, ok := e.GetIntMap("map")
+    assert.False(ok)
+    assert.Panics(func() { e.MustGetDurationMap("map") })
+
+    e.SetDefaultStringMap("map", map[string]string{"tier": "1", "team": "core"})
+    assert.Equal("team=core,tier=1", e.MustGetString("map"))
+    assert.Equal(map[string]string{"team": "core", "tier": "1"}, e.MustGetStringMap("map"))
+    e.SetDefaultStringMap("map", map[string]string{"x": "y"})
+    assert.Equal(map[string]string{"team": "core", "tier": "1"}, e.MustGetStringMap("map"))
+    e.SetStringMap("map", map[string]string{"a": " x ", "b=c": "1", "": "x"})
+    assert.Equal(map[string]string{"a": " x ", "b=c": "1", "": "x"}, e.MustGetStringMap("map"))
+
+    e.SetBoolMap("map", map[string]bool{"a": true, "b": false})
+    assert.Equal(map[string]bool{"a": true, "b": false}, e.MustGetBoolMap("map"))
+    e.SetIntMap("map", map[string]int{"a": 10, "b": -20})
+    assert.Equal(map[string]int{"a": 10, "b": -20}, e.MustGetIntMap("map"))
+    e.SetInt64Map("map", map[string]int64{"a": 1 << 40})
+    assert.Equal(map[string]int64{"a": 1 << 40}, e.MustGetInt64Map("map"))
+    e.SetUIntMap("map", map[string]uint{"a": 1})
+    assert.Equal(map[string]uint{"a": 1}, e.MustGetUIntMap("map"))
+    e.SetUInt64Map("map", map[string]uint64{"a": 1 << 63})
+    assert.Equal(map[string]uint64{"a": 1 << 63}, e.MustGetUInt64Map("map"))
+    e.SetFloat64Map("map", map[string]float64{"a": 0.25})
+    assert.Equal(map[string]float64{"a": 0.25}, e.MustGetFloat64Map("map"))
+    e.SetDurationMap("map", map[string]time.Duration{"read": time.Second, "write": time.Minute})
+    assert.Equal("read=1s,write=1m0s", e.MustGetString("map"))
+    assert.Equal(map[string]time.Duration{"read": time.Second, "write": time.Minute}, e.MustGetDurationMap("map"))
+
+    e.SetString("map", "a=1,b=x")
+    _, err = e.LookupIntMap("map")
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("map[string]int", perr.Type)
+        assert.Equal("APP_MAP", perr.Key)
+    }
+    _, ok = e.GetUInt64Map("map")
+    assert.False(ok)
+    assert.Panics(func() { e.MustGetIntMap("map") })
+
+    e.SetListOptions(ListOptions{Separator: ";", KeyValueSeparator: ":", TrimSpace: true})
+    e.SetString("map", "team:core; tier:1")
+    assert.Equal(map[string]string{"team": "core", "tier": "1"}, e.MustGetStringMap("map"))
+    e.SetIntMap("map", map[string]int{"b": 2, "a": 1})
+    assert.Equal("a:1;b:2", e.MustGetString("map"))
+}
+
+func TestProcessMaps(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        Limits map[string]int    `env:"limits"`
+        Tags   map[string]string `env:"tags" default:"team=core"`
+        Bad    map[int]string    `env:"bad"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_LIMITS": "a=10,b=20"}))
+
+    assert.NoError(e.Process(&cfg))
+    assert.Equal(map[string]int{"a": 10, "b": 20}, cfg.Limits)
+    assert.Equal(map[string]string{"team": "core"}, cfg.Tags)
+
+    e.SetString("bad", "1=x")
+    assert.Error(e.Process(&cfg))
+}
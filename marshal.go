@@ -0,0 +1,137 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "io"
+    "reflect"
+    "sort"
+    "strconv"
+)
+
+// Marshal is the inverse of Process. It formats every field of the struct
+// pointed to by spec and stores it in the Source of e, which must implement
+// Setter, under the key Process reads it from. Slices and maps of structs are
+// stored under indexed and named keys. Map keys become part of the key and
+// are normalized, so Process reads them back in upper case; map keys which
+// normalize to the same key are an error. Fields tagged with `env:"-"` and nil
+// pointers are skipped, all other fields are written even if they hold the
+// zero value.
+func (e *Env) Marshal(spec interface{}) error {
+    setter, ok := e.source.(Setter)
+    if !ok {
+        return errors.New("Source does not support setting environment variables")
+    }
+
+    return e.marshal(spec, func(key, value string) error {
+        return setter.Set(key, value)
+    })
+}
+
+// MarshalEnvFile writes every field of the struct pointed to by spec in .env
+// syntax to w, one KEY="value" line per field, so that LoadFile restores
+// them. See Marshal for details. Values of secret keys are written as they
+// are, not redacted.
+func (e *Env) MarshalEnvFile(w io.Writer, spec interface{}) error {
+    return e.marshal(spec, func(key, value string) error {
+        _, err := io.WriteString(w, key+"="+quoteDotenv(value)+"\n")
+        return err
+    })
+}
+
+// marshal calls fn with the normalized and prefixed key and the formatted
+// value of every field of spec.
+func (e *Env) marshal(spec interface{}, fn func(key, value string) error) error {
+    rv := reflect.ValueOf(spec)
+    if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+        rv = rv.Elem()
+    }
+    if rv.Kind() != reflect.Struct {
+        return errors.New("Marshal requires a struct or a non-nil pointer to a struct")
+    }
+
+    return e.marshalStruct(rv, "", fn)
+}
+
+func (e *Env) marshalStruct(rv reflect.Value, prefix string, fn func(key, value string) error) error {
+    rt := rv.Type()
+    for i := 0; i < rt.NumField(); i++ {
+        field := rt.Field(i)
+        if skipField(field) {
+            continue
+        }
+
+        if sub, ok := nestedPrefix(field, prefix); ok {
+            fv := rv.Field(i)
+            if fv.Kind() == reflect.Ptr {
+                if fv.IsNil() {
+                    continue
+                }
+                fv = fv.Elem()
+            }
+            if err := e.marshalStruct(fv, sub, fn); err != nil {
+                return err
+            }
+            continue
+        }
+        if isCollection(field.Type) {
+            if err := e.marshalCollection(rv.Field(i), joinPrefix(prefix, fieldName(field)), fn); err != nil {
+                return err
+            }
+            continue
+        }
+
+        v, ok := e.fieldVariable(field, prefix)
+        if !ok {
+            continue
+        }
+
+        if fv := rv.Field(i); fv.Kind() == reflect.Ptr && fv.IsNil() {
+            continue
+        }
+        str, err := e.formatValue(rv.Field(i))
+        if err != nil {
+            return errors.New("Can not format type " + v.Type + " for environment variable \"" + v.Key + "\"")
+        }
+        if err := fn(v.Key, str); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// marshalCollection is the inverse of processCollection. Map entries are
+// written in the order of their keys.
+func (e *Env) marshalCollection(fv reflect.Value, prefix string, fn func(key, value string) error) error {
+    if fv.Kind() == reflect.Slice {
+        for i := 0; i < fv.Len(); i++ {
+            if err := e.marshalStruct(fv.Index(i), joinPrefix(prefix, strconv.Itoa(i)), fn); err != nil {
+                return err
+            }
+        }
+
+        return nil
+    }
+
+    keys := fv.MapKeys()
+    sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+    seen := make(map[string]string, len(keys))
+    for _, k := range keys {
+        sub := joinPrefix(prefix, k.String())
+        if other, ok := seen[e.prepareKey(sub)]; ok {
+            return errors.New("Map keys " + strconv.Quote(other) + " and " + strconv.Quote(k.String()) +
+                " of environment variable \"" + e.prepareKey(prefix) + "\" collide")
+        }
+        seen[e.prepareKey(sub)] = k.String()
+
+        if err := e.marshalStruct(fv.MapIndex(k), sub, fn); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
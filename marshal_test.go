@@ -0,0 +1,132 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type marshalTestConfig struct {
+    bindTestCommon
+    Name      string
+    Port      int
+    Debug     bool
+    Timeout   time.Duration
+    Ratio     float64
+    Tags      []string
+    Labels    map[string]int
+    Password  Secret
+    Notes     string           `env:"-"`
+    Primary   bindTestDatabase `env:"primary db"`
+    Upstreams []collectionTestUpstream
+    Regions   map[string]collectionTestUpstream
+}
+
+func newMarshalTestConfig() marshalTestConfig {
+    return marshalTestConfig{
+        bindTestCommon: bindTestCommon{LogLevel: "debug"},
+        Name:           "say \"hello\"\n$HOME\t\\",
+        Port:           8080,
+        Debug:          true,
+        Timeout:        5 * time.Second,
+        Ratio:          0.25,
+        Tags:           []string{"a", "b,c"},
+        Labels:         map[string]int{"x": 1, "y": 2},
+        Password:       "hunter2",
+        Notes:          "not marshaled",
+        Primary:        bindTestDatabase{Host: "db", Port: 5432},
+        Upstreams:      []collectionTestUpstream{{Host: "a", Port: 80}, {Host: "b", Port: 81, TLSCert: "cert"}},
+        Regions:        map[string]collectionTestUpstream{"EU": {Host: "eu", Port: 443}},
+    }
+}
+
+func TestMarshal(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    src := NewMapSource(nil)
+    e.SetSource(src)
+
+    c := newMarshalTestConfig()
+    assert.NoError(e.Marshal(&c))
+    assert.Equal("8080", e.MustGetString("port"))
+    assert.Equal("5s", e.MustGetString("timeout"))
+    assert.Equal(`a,"b,c"`, e.MustGetString("tags"))
+    assert.Equal("x=1,y=2", e.MustGetString("labels"))
+    assert.Equal("hunter2", e.MustGetString("password"))
+    assert.Equal("db", e.MustGetString("primary db host"))
+    assert.Equal("cert", e.MustGetString("upstreams 1 tls cert"))
+    assert.Equal("eu", e.MustGetString("regions EU host"))
+    assert.False(e.IssetKey("notes"))
+
+    var d marshalTestConfig
+    assert.NoError(e.Process(&d))
+    c.Notes = ""
+    assert.Equal(c, d)
+
+    e.SetSource(LookupFunc(func(string) (string, bool) { return "", false }))
+    assert.Error(e.Marshal(&c))
+}
+
+func TestMarshalMapKeys(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("q")
+    e.SetSource(NewMapSource(nil))
+
+    var c, d struct {
+        Ups map[string]collectionTestUpstream
+    }
+    c.Ups = map[string]collectionTestUpstream{"eu": {Host: "eu", Port: 443}, "us west": {Host: "us", Port: 80}}
+    assert.NoError(e.Marshal(&c))
+    assert.Equal("eu", e.MustGetString("ups eu host"))
+    assert.Equal("us", e.MustGetString("ups us west host"))
+
+    // Map keys are normalized like keys
+    assert.NoError(e.Process(&d))
+    assert.Equal(map[string]collectionTestUpstream{"EU": c.Ups["eu"], "US_WEST": c.Ups["us west"]}, d.Ups)
+
+    assert.NoError(e.Marshal(&d))
+    var f struct {
+        Ups map[string]collectionTestUpstream
+    }
+    assert.NoError(e.Process(&f))
+    assert.Equal(d, f)
+
+    c.Ups["EU"] = collectionTestUpstream{Host: "other"}
+    assert.EqualError(e.Marshal(&c), `Map keys "EU" and "eu" of environment variable "Q_UPS" collide`)
+}
+
+func TestMarshalEnvFile(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    c := newMarshalTestConfig()
+
+    var buf bytes.Buffer
+    assert.NoError(e.MarshalEnvFile(&buf, c))
+    assert.True(strings.HasPrefix(buf.String(), "APP_LOG_LEVEL=\"debug\"\n"+
+        "APP_NAME=\"say \\\"hello\\\"\\n\\$HOME\\t\\\\\"\n"+
+        "APP_PORT=\"8080\"\n"))
+    assert.Contains(buf.String(), "APP_PASSWORD=\"hunter2\"\n")
+    assert.Contains(buf.String(), "APP_UPSTREAMS_1_TLS_CERT=\"cert\"\n")
+    assert.Contains(buf.String(), "APP_REGIONS_EU_PORT=\"443\"\n")
+
+    e.SetSource(NewMapSource(nil))
+    assert.NoError(e.LoadReader(&buf, ""))
+
+    var d marshalTestConfig
+    assert.NoError(e.Process(&d))
+    c.Notes = ""
+    assert.Equal(c, d)
+
+    assert.Error(e.MarshalEnvFile(&buf, 42))
+    assert.Error(e.MarshalEnvFile(&buf, struct{ C chan int }{}))
+}
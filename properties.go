@@ -0,0 +1,171 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf16"
+)
+
+// PropertiesFileSource reads a Java .properties file into a FileSource. See
+// PropertiesSource for details.
+func (e *Env) PropertiesFileSource(path string) (*FileSource, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    return e.PropertiesSource(f, path)
+}
+
+// PropertiesSource reads a Java .properties document from r into a
+// FileSource whose keys are those e looks up. The key db.maxConns is stored
+// under the key e.prepareKey("db max conns"), e.g. APP_DB_MAX_CONNS.
+//
+// The syntax is the one of java.util.Properties: the key is separated from
+// the value by '=', ':' or whitespace. Lines whose first non-blank character
+// is '#' or '!' are comments. A line ending with an odd number of
+// backslashes is continued on the next line, whose leading blanks are
+// skipped. Keys and values may contain the escape sequences \t, \n, \r, \f
+// and \uXXXX, a backslash before any other character is dropped.
+//
+// The name is used in error messages and origins; a malformed document is
+// reported as *SyntaxError with the line of the error.
+func (e *Env) PropertiesSource(r io.Reader, name string) (*FileSource, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    s := newFileSource(name)
+    errorf := func(line int, msg string) error {
+        return &SyntaxError{File: name, Line: line, Msg: msg}
+    }
+
+    lines := strings.Split(string(data), "\n")
+    for i := 0; i < len(lines); i++ {
+        line := i + 1
+        text := strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t\f")
+        if text == "" || text[0] == '#' || text[0] == '!' {
+            continue
+        }
+
+        // Join continuation lines.
+        for continued(text) && i+1 < len(lines) {
+            i++
+            text = text[:len(text)-1] + strings.TrimLeft(strings.TrimSuffix(lines[i], "\r"), " \t\f")
+        }
+        if continued(text) {
+            text = text[:len(text)-1]
+        }
+
+        key, value := splitProperty(text)
+        key, err := unescapeProperty(key)
+        if err != nil {
+            return nil, errorf(line, err.Error()+" in key")
+        }
+        if key == "" {
+            return nil, errorf(line, "missing key")
+        }
+        value, err = unescapeProperty(value)
+        if err != nil {
+            return nil, errorf(line, err.Error()+" in value of key "+strconv.Quote(key))
+        }
+
+        s.set(e.prepareKey(keyWords(key)), value, line)
+    }
+
+    return s, nil
+}
+
+// continued reports whether text ends with an odd number of backslashes.
+func continued(text string) bool {
+    n := len(text) - len(strings.TrimRight(text, "\\"))
+    return n%2 == 1
+}
+
+// splitProperty splits text at the first unescaped separator into the still
+// escaped key and value.
+func splitProperty(text string) (string, string) {
+    for i := 0; i < len(text); i++ {
+        switch text[i] {
+        case '\\':
+            i++
+        case '=', ':':
+            return text[:i], strings.TrimLeft(text[i+1:], " \t\f")
+        case ' ', '\t', '\f':
+            rest := strings.TrimLeft(text[i:], " \t\f")
+            if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+                rest = rest[1:]
+            }
+            return text[:i], strings.TrimLeft(rest, " \t\f")
+        }
+    }
+
+    return text, ""
+}
+
+// unescapeProperty replaces the escape sequences of s.
+func unescapeProperty(s string) (string, error) {
+    if !strings.Contains(s, "\\") {
+        return s, nil
+    }
+
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        if s[i] != '\\' || i+1 == len(s) {
+            b.WriteByte(s[i])
+            continue
+        }
+
+        i++
+        switch s[i] {
+        case 't':
+            b.WriteByte('\t')
+        case 'n':
+            b.WriteByte('\n')
+        case 'r':
+            b.WriteByte('\r')
+        case 'f':
+            b.WriteByte('\f')
+        case 'u':
+            r, ok := unicodeEscape(s[i+1:])
+            if !ok {
+                return "", errors.New("malformed \\uXXXX escape sequence")
+            }
+            i += 4
+
+            // Characters outside the BMP are escaped as surrogate pair.
+            if utf16.IsSurrogate(r) && strings.HasPrefix(s[i+1:], "\\u") {
+                r2, ok := unicodeEscape(s[i+3:])
+                if c := utf16.DecodeRune(r, r2); ok && c != unicode.ReplacementChar {
+                    r = c
+                    i += 6
+                }
+            }
+            b.WriteRune(r)
+        default:
+            b.WriteByte(s[i])
+        }
+    }
+
+    return b.String(), nil
+}
+
+// unicodeEscape parses the four hex digits at the start of s.
+func unicodeEscape(s string) (rune, bool) {
+    if len(s) < 4 {
+        return 0, false
+    }
+
+    r, err := strconv.ParseUint(s[:4], 16, 16)
+    return rune(r), err == nil
+}
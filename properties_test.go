@@ -0,0 +1,77 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+const propertiesTestDocument = `# comment
+! another comment
+name=demo
+db.host : db.example.com
+db.maxConns 10
+greeting = Gr\u00fc\u00dfe \ud83d\ude00
+message = first line \
+          second line
+path = C:\\temp\\
+key\ with\ spaces = x\=y
+tabs = a\tb
+empty
+`
+
+func TestPropertiesSource(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    src, err := e.PropertiesSource(strings.NewReader(propertiesTestDocument), "application.properties")
+    assert.NoError(err)
+    e.SetSource(src)
+
+    assert.Equal("demo", e.MustGetString("name"))
+    assert.Equal("db.example.com", e.MustGetString("db host"))
+    assert.Equal(10, e.MustGetInt("db max conns"))
+    assert.Equal("Grüße 😀", e.MustGetString("greeting"))
+    assert.Equal("first line second line", e.MustGetString("message"))
+    assert.Equal(`C:\temp\`, e.MustGetString("path"))
+    assert.Equal("x=y", e.MustGetString("key with spaces"))
+    assert.Equal("a\tb", e.MustGetString("tabs"))
+    assert.Equal("", e.MustGetString("empty"))
+
+    origin, ok := src.Origin("APP_DB_MAX_CONNS")
+    assert.True(ok)
+    assert.Equal(Origin{File: "application.properties", Line: 5}, origin)
+    origin, _ = src.Origin("APP_PATH")
+    assert.Equal(9, origin.Line)
+}
+
+func TestPropertiesSourceError(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("")
+    for _, tt := range []struct {
+        doc  string
+        line int
+    }{
+        {"a = 1\nb = \\u12", 2},
+        {"a = 1\n\nb = \\uXYZW", 3},
+        {"\\u00zz = 1", 1},
+        {"a = 1\n= 2", 2},
+    } {
+        _, err := e.PropertiesSource(strings.NewReader(tt.doc), "application.properties")
+        var serr *SyntaxError
+        if assert.True(errors.As(err, &serr), tt.doc) {
+            assert.Equal("application.properties", serr.File)
+            assert.Equal(tt.line, serr.Line, tt.doc)
+        }
+    }
+
+    _, err := e.PropertiesFileSource("testdata/missing.properties")
+    assert.Error(err)
+}
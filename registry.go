@@ -0,0 +1,357 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "reflect"
+    "sync"
+)
+
+// Parser converts the value of an environment variable to type T.
+type Parser[T any] func(str string) (T, error)
+
+// Formatter converts a value of type T to the value of an environment
+// variable. It must produce a string which the Parser of T accepts.
+type Formatter[T any] func(value T) string
+
+// converter holds the type-erased Parser and Formatter of a type. Either
+// function is nil if the type can not be parsed or formatted.
+type converter struct {
+    parse  func(str string) (interface{}, error)
+    format func(value interface{}) (string, error)
+}
+
+var registry = struct {
+    sync.RWMutex
+    converters map[reflect.Type]converter
+}{converters: make(map[reflect.Type]converter)}
+
+func init() {
+    Register(parseString, formatString)
+    Register(parseBoolError, formatBool)
+    Register(parseDuration, formatDuration)
+    Register(parseFloat64, formatFloat64)
+    Register(parseInt, formatInt)
+    Register(parseInt64, formatInt64)
+    Register(parseUInt, formatUInt)
+    Register(parseUInt64, formatUInt64)
+}
+
+// Register makes the type T available to Get, Set, struct binding and all
+// other generic functions, including slices of T and maps from string to T.
+// A previous registration of T is replaced. If parse or format is nil, T
+// can not be parsed or formatted respectively.
+//
+// The types string, bool, int, int64, uint, uint64, float64 and
+// time.Duration are registered by default.
+func Register[T any](parse Parser[T], format Formatter[T]) {
+    var c converter
+    if parse != nil {
+        c.parse = func(str string) (interface{}, error) {
+            return parse(str)
+        }
+    }
+    if format != nil {
+        c.format = func(value interface{}) (string, error) {
+            return format(value.(T)), nil
+        }
+    }
+
+    registry.Lock()
+    defer registry.Unlock()
+
+    registry.converters[typeOf[T]()] = c
+}
+
+func typeOf[T any]() reflect.Type {
+    return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// basicTypes maps kinds to the registered type used for named types of
+// that kind which are not registered themselves, e.g. type Port int.
+var basicTypes = map[reflect.Kind]reflect.Type{
+    reflect.String:  typeOf[string](),
+    reflect.Bool:    typeOf[bool](),
+    reflect.Float64: typeOf[float64](),
+    reflect.Int:     typeOf[int](),
+    reflect.Int64:   typeOf[int64](),
+    reflect.Uint:    typeOf[uint](),
+    reflect.Uint64:  typeOf[uint64](),
+}
+
+// lookupConverter returns the converter of t and the type it operates on,
+// which differs from t for named types falling back to their basic type.
+// Registered types take precedence over types implementing
+// encoding.TextUnmarshaler or encoding.TextMarshaler, which take precedence
+// over the basic types. A named type implementing only one of them is
+// parsed or formatted as its basic type otherwise.
+func lookupConverter(t reflect.Type) (converter, reflect.Type, bool) {
+    registry.RLock()
+    defer registry.RUnlock()
+
+    if c, ok := registry.converters[t]; ok {
+        return c, t, true
+    }
+
+    if c, ok := textConverter(t); ok {
+        if base, ok := basicTypes[t.Kind()]; ok {
+            c = completeConverter(c, t, registry.converters[base], base)
+        }
+        return c, t, true
+    }
+
+    if base, ok := basicTypes[t.Kind()]; ok {
+        c, ok := registry.converters[base]
+        return c, base, ok
+    }
+
+    return converter{}, nil, false
+}
+
+// lookupTypeName returns the registered type whose name is name, e.g. int
+// or time.Duration.
+func lookupTypeName(name string) (reflect.Type, bool) {
+    registry.RLock()
+    defer registry.RUnlock()
+
+    for t := range registry.converters {
+        if t.String() == name {
+            return t, true
+        }
+    }
+
+    return nil, false
+}
+
+// completeConverter fills the missing functions of c, the converter of t,
+// with those of b, the converter of its basic type base.
+func completeConverter(c converter, t reflect.Type, b converter, base reflect.Type) converter {
+    if c.parse == nil && b.parse != nil {
+        c.parse = func(str string) (interface{}, error) {
+            v, err := b.parse(str)
+            if err != nil {
+                return nil, err
+            }
+            return reflect.ValueOf(v).Convert(t).Interface(), nil
+        }
+    }
+    if c.format == nil && b.format != nil {
+        c.format = func(value interface{}) (string, error) {
+            return b.format(reflect.ValueOf(value).Convert(base).Interface())
+        }
+    }
+
+    return c
+}
+
+// errUnsupported is returned for types without a registered Parser.
+var errUnsupported = errors.New("unsupported type")
+
+// parseValue converts str to a value of type t. Besides registered types and
+// named types based on the built-in ones, slices of them and maps from string
+// to them are supported.
+func (e *Env) parseValue(t reflect.Type, str string) (reflect.Value, error) {
+    if c, _, ok := lookupConverter(t); ok {
+        if c.parse == nil {
+            return reflect.Value{}, errUnsupported
+        }
+
+        v, err := c.parse(str)
+        if err != nil {
+            return reflect.Value{}, err
+        }
+
+        return reflect.ValueOf(v).Convert(t), nil
+    }
+
+    switch {
+    case t.Kind() == reflect.Slice && isScalar(t.Elem()):
+        elems, err := splitList(str, e.list)
+        if err != nil {
+            return reflect.Value{}, err
+        }
+
+        slice := reflect.MakeSlice(t, len(elems), len(elems))
+        for i, elem := range elems {
+            v, err := e.parseValue(t.Elem(), elem)
+            if err != nil {
+                return reflect.Value{}, err
+            }
+            slice.Index(i).Set(v)
+        }
+
+        return slice, nil
+    case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && isScalar(t.Elem()):
+        entries, err := splitMap(str, e.list)
+        if err != nil {
+            return reflect.Value{}, err
+        }
+
+        m := reflect.MakeMapWithSize(t, len(entries))
+        for k, elem := range entries {
+            v, err := e.parseValue(t.Elem(), elem)
+            if err != nil {
+                return reflect.Value{}, err
+            }
+            m.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), v)
+        }
+
+        return m, nil
+    }
+
+    return reflect.Value{}, errUnsupported
+}
+
+// formatValue is the inverse of parseValue.
+func (e *Env) formatValue(v reflect.Value) (string, error) {
+    t := v.Type()
+    if c, base, ok := lookupConverter(t); ok {
+        if c.format == nil {
+            return "", errUnsupported
+        }
+
+        return c.format(v.Convert(base).Interface())
+    }
+
+    switch {
+    case t.Kind() == reflect.Slice && isScalar(t.Elem()):
+        elems := make([]string, v.Len())
+        for i := range elems {
+            str, err := e.formatValue(v.Index(i))
+            if err != nil {
+                return "", err
+            }
+            elems[i] = str
+        }
+
+        return joinList(elems, e.list), nil
+    case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && isScalar(t.Elem()):
+        entries := make(map[string]string, v.Len())
+        iter := v.MapRange()
+        for iter.Next() {
+            str, err := e.formatValue(iter.Value())
+            if err != nil {
+                return "", err
+            }
+            entries[iter.Key().String()] = str
+        }
+
+        return joinMap(entries, e.list), nil
+    }
+
+    return "", errUnsupported
+}
+
+// canParse reports whether parseValue supports t.
+func canParse(t reflect.Type) bool {
+    if c, _, ok := lookupConverter(t); ok {
+        return c.parse != nil
+    }
+
+    switch {
+    case t.Kind() == reflect.Slice:
+        return isScalar(t.Elem()) && canParse(t.Elem())
+    case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+        return isScalar(t.Elem()) && canParse(t.Elem())
+    }
+
+    return false
+}
+
+// isScalar reports whether t can be used as element of a slice or map.
+func isScalar(t reflect.Type) bool {
+    _, _, ok := lookupConverter(t)
+    return ok
+}
+
+// GetFrom returns the environment variable key of e parsed as T.
+// The error matches ErrNotSet if the environment does not exist, is a
+// *ParseError if it could not be parsed or a *RuleError if it violates a
+// rule added with AddRules.
+func GetFrom[T any](e *Env, key string) (T, error) {
+    var value T
+
+    v, err := e.lookupValue(key, typeOf[T]())
+    if err != nil {
+        return value, err
+    }
+
+    return v.Interface().(T), nil
+}
+
+// lookupValue returns the environment variable key of e parsed as t. See
+// GetFrom for the errors.
+func (e *Env) lookupValue(key string, t reflect.Type) (reflect.Value, error) {
+    str, err := e.lookup(key)
+    if err != nil {
+        return reflect.Value{}, err
+    }
+
+    v, err := e.parseValue(t, str)
+    if err != nil {
+        return reflect.Value{}, e.parseError(e.prepareKey(key), str, t.String(), err)
+    }
+    if err := e.checkRules(e.prepareKey(key), str, v, nil); err != nil {
+        return reflect.Value{}, err
+    }
+
+    return v, nil
+}
+
+// MustGetFrom returns the environment variable key of e parsed as T
+// if possible, otherwise it panics.
+func MustGetFrom[T any](e *Env, key string) T {
+    value, err := GetFrom[T](e, key)
+    if err != nil {
+        panic(err.Error())
+    }
+
+    return value
+}
+
+// SetIn sets the environment variable key of e to the formatted value.
+// It panics if T is not supported.
+func SetIn[T any](e *Env, key string, value T) {
+    str, err := e.formatValue(reflect.ValueOf(&value).Elem())
+    if err != nil {
+        panic("Can not format type " + typeOf[T]().String() + " for environment variable \"" +
+            e.prepareKey(key) + "\"")
+    }
+
+    e.SetString(key, str)
+}
+
+// DefaultIn sets the environment variable key of e to the formatted value
+// if it is not already set.
+func DefaultIn[T any](e *Env, key string, value T) {
+    if !e.IssetKey(key) {
+        SetIn(e, key, value)
+    }
+}
+
+// Get returns the environment variable key parsed as T.
+// See GetFrom for details.
+func Get[T any](key string) (T, error) {
+    return GetFrom[T](std, key)
+}
+
+// MustGet returns the environment variable key parsed as T
+// if possible, otherwise it panics.
+func MustGet[T any](key string) T {
+    return MustGetFrom[T](std, key)
+}
+
+// Set sets the environment variable key to the formatted value.
+// It panics if T is not supported.
+func Set[T any](key string, value T) {
+    SetIn(std, key, value)
+}
+
+// Default sets the environment variable key to the formatted value
+// if it is not already set.
+func Default[T any](key string, value T) {
+    DefaultIn(std, key, value)
+}
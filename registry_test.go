@@ -0,0 +1,132 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "net/url"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type registryTestPort int
+
+type registryTestLevel int8
+
+type registryTestToken int8
+
+func init() {
+    Register(url.Parse, (*url.URL).String)
+}
+
+func TestGeneric(t *testing.T) {
+    assert := assert.New(t)
+
+    UnsetKey("envconf_generic")
+    defer UnsetKey("envconf_generic")
+
+    _, err := Get[int]("envconf_generic")
+    assert.True(errors.Is(err, ErrNotSet))
+    assert.Panics(func() { MustGet[int]("envconf_generic") })
+
+    Default("envconf_generic", 42)
+    v, err := Get[int]("envconf_generic")
+    assert.NoError(err)
+    assert.Equal(42, v)
+
+    Default("envconf_generic", 43)
+    assert.Equal(42, MustGet[int]("envconf_generic"))
+
+    Set("envconf_generic", 90*time.Second)
+    assert.Equal("1m30s", MustGet[string]("envconf_generic"))
+    assert.Equal(90*time.Second, MustGet[time.Duration]("envconf_generic"))
+
+    _, err = Get[uint]("envconf_generic")
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("uint", perr.Type)
+    }
+}
+
+func TestGenericCollections(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    SetIn(e, "ports", []registryTestPort{80, 443})
+    assert.Equal("80,443", e.MustGetString("ports"))
+    assert.Equal([]registryTestPort{80, 443}, MustGetFrom[[]registryTestPort](e, "ports"))
+
+    SetIn(e, "limits", map[string]float64{"b": 0.5, "a": 2})
+    assert.Equal("a=2,b=0.5", e.MustGetString("limits"))
+    assert.Equal(map[string]float64{"a": 2, "b": 0.5}, MustGetFrom[map[string]float64](e, "limits"))
+
+    DefaultIn(e, "limits", map[string]float64{})
+    assert.Equal("a=2,b=0.5", e.MustGetString("limits"))
+}
+
+func TestRegister(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    u, _ := url.Parse("https://example.com/path")
+    SetIn(e, "url", u)
+    assert.Equal("https://example.com/path", e.MustGetString("url"))
+
+    v, err := GetFrom[*url.URL](e, "url")
+    assert.NoError(err)
+    assert.Equal("example.com", v.Host)
+
+    e.SetString("url", "http://[::1")
+    _, err = GetFrom[*url.URL](e, "url")
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("*url.URL", perr.Type)
+    }
+
+    e.SetString("urls", "http://a, http://b")
+    urls := MustGetFrom[[]*url.URL](e, "urls")
+    if assert.Len(urls, 2) {
+        assert.Equal("b", urls[1].Host)
+    }
+
+    var cfg struct {
+        Endpoint *url.URL `env:"url" default:"http://localhost"`
+    }
+    e.UnsetKey("url")
+    assert.NoError(e.Process(&cfg))
+    assert.Equal("localhost", cfg.Endpoint.Host)
+}
+
+func TestUnsupportedType(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_VALUE": "1+2i"}))
+
+    _, err := GetFrom[complex128](e, "value")
+    assert.True(errors.Is(err, errUnsupported))
+    _, err = GetFrom[[][]int](e, "value")
+    assert.True(errors.Is(err, errUnsupported))
+    _, err = GetFrom[map[int]int](e, "value")
+    assert.True(errors.Is(err, errUnsupported))
+
+    assert.Panics(func() { SetIn(e, "value", 1+2i) })
+
+    Register[registryTestLevel](nil, func(l registryTestLevel) string { return "level" })
+    SetIn(e, "value", registryTestLevel(1))
+    assert.Equal("level", e.MustGetString("value"))
+    _, err = GetFrom[registryTestLevel](e, "value")
+    assert.True(errors.Is(err, errUnsupported))
+
+    Register(func(string) (registryTestToken, error) { return 7, nil }, nil)
+    assert.Equal(registryTestToken(7), MustGetFrom[registryTestToken](e, "value"))
+    assert.Panics(func() { SetIn(e, "value", registryTestToken(1)) })
+}
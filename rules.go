@@ -0,0 +1,335 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "fmt"
+    "reflect"
+    "regexp"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// Rule is a constraint on the parsed value of an environment variable.
+// Rules are attached to keys with AddRules or to struct fields with tags and
+// checked after the value has been parsed successfully.
+type Rule struct {
+    name  string
+    check func(e *Env, v reflect.Value) error
+}
+
+// String returns the name of the rule, e.g. "min=1".
+func (r Rule) String() string {
+    return r.name
+}
+
+// RuleError is returned if the value of an environment variable violates a
+// Rule. For secret keys Value is Redacted.
+type RuleError struct {
+    Key   string // normalized and prefixed key
+    Value string // raw value of the environment variable
+    Rule  string // name of the violated rule, e.g. "max=65535"
+    Err   error  // describes the violation, e.g. "must be at most 65535"
+}
+
+func (e *RuleError) Error() string {
+    return "Environment variable \"" + e.Key + "\" violates rule " + e.Rule + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *RuleError) Unwrap() error {
+    return e.Err
+}
+
+// AddRules attaches rules to key. They are checked by GetFrom, all typed
+// Lookup and Get functions, Process and VarSet.Parse whenever key has a
+// value. Rules of unset variables are not checked.
+func (e *Env) AddRules(key string, rules ...Rule) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+
+    if e.rules == nil {
+        e.rules = make(map[string][]Rule)
+    }
+
+    key = e.prepareKey(key)
+    e.rules[key] = append(e.rules[key], rules...)
+}
+
+// checkRules checks the value v parsed from str against the rules of the
+// prepared key followed by extra. It returns a *RuleError for the first
+// violated rule.
+func (e *Env) checkRules(key, str string, v reflect.Value, extra []Rule) error {
+    e.mu.RLock()
+    rules := append(e.rules[key][:len(e.rules[key]):len(e.rules[key])], extra...)
+    e.mu.RUnlock()
+
+    for _, r := range rules {
+        if err := r.check(e, v); err != nil {
+            return &RuleError{Key: key, Value: e.redact(key, str), Rule: r.name, Err: err}
+        }
+    }
+
+    return nil
+}
+
+// Min requires the value to be greater than or equal to bound. The bound is
+// converted to the type of the value, a string is parsed like an environment
+// variable, so Min("1s") works for time.Duration. Min supports integer and
+// floating-point types.
+func Min(bound interface{}) Rule {
+    return compareRule("min", bound, func(c int) bool { return c >= 0 }, "must be at least ")
+}
+
+// Max requires the value to be less than or equal to bound.
+// See Min for details.
+func Max(bound interface{}) Rule {
+    return compareRule("max", bound, func(c int) bool { return c <= 0 }, "must be at most ")
+}
+
+func compareRule(name string, bound interface{}, ok func(c int) bool, msg string) Rule {
+    return Rule{name: name + "=" + fmt.Sprint(bound), check: func(e *Env, v reflect.Value) error {
+        b, err := ruleOperand(e, v.Type(), bound)
+        if err != nil {
+            return err
+        }
+
+        var c int
+        switch v.Kind() {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            c = compareOrdered(v.Int(), b.Int())
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+            c = compareOrdered(v.Uint(), b.Uint())
+        case reflect.Float32, reflect.Float64:
+            c = compareOrdered(v.Float(), b.Float())
+        default:
+            return errors.New("requires a numeric type, got " + v.Type().String())
+        }
+
+        if !ok(c) {
+            return errors.New(msg + formatOperand(e, b))
+        }
+
+        return nil
+    }}
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+    switch {
+    case a < b:
+        return -1
+    case a > b:
+        return 1
+    }
+
+    return 0
+}
+
+// OneOf requires the value to be equal to one of values. The values are
+// converted like the bound of Min.
+func OneOf(values ...interface{}) Rule {
+    names := make([]string, len(values))
+    for i, v := range values {
+        names[i] = fmt.Sprint(v)
+    }
+
+    return Rule{name: "oneof=" + strings.Join(names, " "), check: func(e *Env, v reflect.Value) error {
+        allowed := make([]string, len(values))
+        for i, value := range values {
+            b, err := ruleOperand(e, v.Type(), value)
+            if err != nil {
+                return err
+            }
+            if reflect.DeepEqual(v.Interface(), b.Interface()) {
+                return nil
+            }
+            allowed[i] = formatOperand(e, b)
+        }
+
+        return errors.New("must be one of " + strings.Join(allowed, ", "))
+    }}
+}
+
+// Pattern requires the value to contain a match of the regular expression
+// expr, use ^ and $ to match the whole value. Values which are not strings
+// are formatted first. An invalid expression fails every check.
+func Pattern(expr string) Rule {
+    re, cerr := regexp.Compile(expr)
+    return Rule{name: "pattern=" + expr, check: func(e *Env, v reflect.Value) error {
+        if cerr != nil {
+            return cerr
+        }
+
+        str, err := ruleString(e, v)
+        if err != nil {
+            return err
+        }
+        if !re.MatchString(str) {
+            return errors.New("must match " + strconv.Quote(expr))
+        }
+
+        return nil
+    }}
+}
+
+// MinLen requires strings to have at least n characters and slices and maps
+// to have at least n elements.
+func MinLen(n int) Rule {
+    return Rule{name: "minlen=" + strconv.Itoa(n), check: func(e *Env, v reflect.Value) error {
+        l, unit, err := ruleLen(v)
+        if err != nil {
+            return err
+        }
+        if l < n {
+            return errors.New("must have at least " + strconv.Itoa(n) + " " + unit)
+        }
+
+        return nil
+    }}
+}
+
+// MaxLen requires strings to have at most n characters and slices and maps
+// to have at most n elements.
+func MaxLen(n int) Rule {
+    return Rule{name: "maxlen=" + strconv.Itoa(n), check: func(e *Env, v reflect.Value) error {
+        l, unit, err := ruleLen(v)
+        if err != nil {
+            return err
+        }
+        if l > n {
+            return errors.New("must have at most " + strconv.Itoa(n) + " " + unit)
+        }
+
+        return nil
+    }}
+}
+
+// NonZero requires the value to be different from the zero value of its
+// type. Strings, slices and maps must not be empty.
+func NonZero() Rule {
+    return Rule{name: "nonzero", check: func(e *Env, v reflect.Value) error {
+        switch v.Kind() {
+        case reflect.String, reflect.Slice, reflect.Map:
+            if v.Len() == 0 {
+                return errors.New("must not be empty")
+            }
+        default:
+            if v.IsZero() {
+                return errors.New("must not be zero")
+            }
+        }
+
+        return nil
+    }}
+}
+
+// Func returns a Rule calling fn with the parsed value. The value must be
+// convertible to T.
+func Func[T any](fn func(value T) error) Rule {
+    return Rule{name: "func", check: func(e *Env, v reflect.Value) error {
+        t := typeOf[T]()
+        if !v.Type().ConvertibleTo(t) {
+            return errors.New("requires type " + t.String() + ", got " + v.Type().String())
+        }
+
+        return fn(v.Convert(t).Interface().(T))
+    }}
+}
+
+// ruleOperand converts the operand x of a rule to type t.
+func ruleOperand(e *Env, t reflect.Type, x interface{}) (reflect.Value, error) {
+    if str, ok := x.(string); ok && t.Kind() != reflect.String {
+        v, err := e.parseValue(t, str)
+        if err != nil {
+            return reflect.Value{}, errors.New("invalid operand " + strconv.Quote(str) + " for " + t.String())
+        }
+
+        return v, nil
+    }
+
+    v := reflect.ValueOf(x)
+    if !v.IsValid() || !v.Type().ConvertibleTo(t) || (t.Kind() == reflect.String) != (v.Kind() == reflect.String) {
+        return reflect.Value{}, errors.New("invalid operand " + fmt.Sprint(x) + " for " + t.String())
+    }
+
+    return v.Convert(t), nil
+}
+
+// formatOperand formats v like an environment variable if possible.
+func formatOperand(e *Env, v reflect.Value) string {
+    if str, err := e.formatValue(v); err == nil {
+        return str
+    }
+
+    return fmt.Sprint(v.Interface())
+}
+
+// ruleString returns v as string, formatting non-string values.
+func ruleString(e *Env, v reflect.Value) (string, error) {
+    if v.Kind() == reflect.String {
+        return v.String(), nil
+    }
+
+    return e.formatValue(v)
+}
+
+// ruleLen returns the length of v and its unit.
+func ruleLen(v reflect.Value) (int, string, error) {
+    switch v.Kind() {
+    case reflect.String:
+        return utf8.RuneCountInString(v.String()), "characters", nil
+    case reflect.Slice, reflect.Map, reflect.Array:
+        return v.Len(), "elements", nil
+    }
+
+    return 0, "", errors.New("requires a string, slice or map, got " + v.Type().String())
+}
+
+// tagRules returns the rules declared by the tags of a struct field.
+func tagRules(tag reflect.StructTag) []Rule {
+    var rules []Rule
+    if tagBool(tag, "nonzero") {
+        rules = append(rules, NonZero())
+    }
+    if str, ok := tag.Lookup("min"); ok {
+        rules = append(rules, Min(str))
+    }
+    if str, ok := tag.Lookup("max"); ok {
+        rules = append(rules, Max(str))
+    }
+    for _, name := range []string{"minlen", "maxlen"} {
+        str, ok := tag.Lookup(name)
+        if !ok {
+            continue
+        }
+
+        n, err := strconv.Atoi(str)
+        switch {
+        case err != nil:
+            rules = append(rules, Rule{name: name + "=" + str, check: func(*Env, reflect.Value) error {
+                return errors.New("invalid length " + strconv.Quote(str))
+            }})
+        case name == "minlen":
+            rules = append(rules, MinLen(n))
+        default:
+            rules = append(rules, MaxLen(n))
+        }
+    }
+    if str, ok := tag.Lookup("oneof"); ok {
+        fields := strings.Fields(str)
+        values := make([]interface{}, len(fields))
+        for i, f := range fields {
+            values[i] = f
+        }
+        rules = append(rules, OneOf(values...))
+    }
+    if str, ok := tag.Lookup("pattern"); ok {
+        rules = append(rules, Pattern(str))
+    }
+
+    return rules
+}
@@ -0,0 +1,160 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func checkRule(r Rule, value interface{}) error {
+    return r.check(New(""), reflect.ValueOf(value))
+}
+
+func TestRules(t *testing.T) {
+    assert := assert.New(t)
+
+    assert.NoError(checkRule(Min(1), 1))
+    assert.EqualError(checkRule(Min(1), 0), "must be at least 1")
+    assert.NoError(checkRule(Max(65535), 65535))
+    assert.EqualError(checkRule(Max(65535), 99999), "must be at most 65535")
+    assert.NoError(checkRule(Min("1"), uint(1)))
+    assert.EqualError(checkRule(Max(0.5), 0.75), "must be at most 0.5")
+    assert.EqualError(checkRule(Min("1s"), time.Millisecond), "must be at least 1s")
+    assert.NoError(checkRule(Max(time.Minute), time.Second))
+    assert.Error(checkRule(Min(1), "a"))
+    assert.Error(checkRule(Min("x"), 1))
+    assert.Equal("min=1", Min(1).String())
+
+    assert.NoError(checkRule(OneOf("debug", "info"), "info"))
+    assert.EqualError(checkRule(OneOf("debug", "info"), "verbose"), "must be one of debug, info")
+    assert.NoError(checkRule(OneOf("1", 2), 2))
+    assert.Error(checkRule(OneOf(1), "1"))
+    assert.Equal("oneof=debug info", OneOf("debug", "info").String())
+
+    assert.NoError(checkRule(Pattern("^[a-z]+$"), "abc"))
+    assert.EqualError(checkRule(Pattern("^[a-z]+$"), "ABC"), `must match "^[a-z]+$"`)
+    assert.NoError(checkRule(Pattern("^8"), 8080))
+    assert.Error(checkRule(Pattern("("), "abc"))
+
+    assert.NoError(checkRule(MinLen(3), "äöü"))
+    assert.EqualError(checkRule(MinLen(3), "ab"), "must have at least 3 characters")
+    assert.EqualError(checkRule(MaxLen(1), []int{1, 2}), "must have at most 1 elements")
+    assert.NoError(checkRule(MaxLen(1), map[string]int{"a": 1}))
+    assert.Error(checkRule(MinLen(1), 1))
+
+    assert.NoError(checkRule(NonZero(), 1))
+    assert.EqualError(checkRule(NonZero(), 0), "must not be zero")
+    assert.EqualError(checkRule(NonZero(), ""), "must not be empty")
+    assert.EqualError(checkRule(NonZero(), []string{}), "must not be empty")
+
+    even := Func(func(v int) error {
+        if v%2 != 0 {
+            return errors.New("must be even")
+        }
+        return nil
+    })
+    assert.NoError(checkRule(even, 2))
+    assert.EqualError(checkRule(even, 3), "must be even")
+    assert.Error(checkRule(even, "2"))
+}
+
+func TestAddRules(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PORT":      "99999",
+        "APP_LOG_LEVEL": "verbose",
+        "APP_PASSWORD":  "hunter2",
+    }))
+    e.AddRules("port", Min(1), Max(65535))
+    e.AddRules("log level", OneOf("debug", "info", "warn"))
+    e.AddRules("password", MinLen(12))
+
+    _, err := e.LookupInt("port")
+    var rerr *RuleError
+    assert.True(errors.As(err, &rerr))
+    assert.Equal(&RuleError{Key: "APP_PORT", Value: "99999", Rule: "max=65535", Err: rerr.Err}, rerr)
+    assert.EqualError(err, `Environment variable "APP_PORT" violates rule max=65535: must be at most 65535`)
+
+    _, err = e.LookupString("log level")
+    assert.EqualError(err, `Environment variable "APP_LOG_LEVEL" violates rule oneof=debug info warn: must be one of debug, info, warn`)
+    _, ok := e.GetString("log level")
+    assert.False(ok)
+
+    _, err = GetFrom[string](e, "password")
+    assert.True(errors.As(err, &rerr))
+    assert.Equal(Redacted, rerr.Value)
+    assert.NotContains(err.Error(), "hunter2")
+
+    e.SetString("port", "8080")
+    assert.Equal(8080, e.MustGetInt("port"))
+    assert.Panics(func() { e.MustGetString("log level") })
+}
+
+func TestProcessRules(t *testing.T) {
+    assert := assert.New(t)
+
+    type config struct {
+        Port     int           `default:"8080" min:"1" max:"65535"`
+        LogLevel string        `default:"info" oneof:"debug info warn"`
+        Name     string        `pattern:"^[a-z]+$" minlen:"2" maxlen:"8"`
+        Tags     []string      `nonzero:"true"`
+        Timeout  time.Duration `default:"5s" min:"1s"`
+        Retries  int           `minlen:"x"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_NAME": "svc"}))
+
+    var c config
+    assert.NoError(e.Process(&c))
+    assert.Equal(8080, c.Port)
+    assert.Equal("info", c.LogLevel)
+    assert.Equal("svc", c.Name)
+
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PORT":      "0",
+        "APP_LOG_LEVEL": "verbose",
+        "APP_NAME":      "Service",
+        "APP_TAGS":      "",
+        "APP_TIMEOUT":   "1s",
+        "APP_RETRIES":   "3",
+    }))
+    e.AddRules("timeout", Max("500ms"))
+
+    c = config{Port: 1}
+    err := e.Process(&c)
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 6)
+    assert.Equal("invalid value \"0\": must be at least 1", verr.Problems[0].Description())
+    assert.Equal("invalid value \"verbose\": must be one of debug, info, warn", verr.Problems[1].Description())
+    assert.Equal("invalid value \"Service\": must match \"^[a-z]+$\"", verr.Problems[2].Description())
+    assert.Equal("invalid value \"\": must not be empty", verr.Problems[3].Description())
+    assert.Equal("invalid value \"1s\": must be at most 500ms", verr.Problems[4].Description())
+    assert.Equal("invalid value \"3\": invalid length \"x\"", verr.Problems[5].Description())
+    assert.Equal(1, c.Port)
+}
+
+func TestVarSetRules(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("")
+    e.SetSource(NewMapSource(map[string]string{"PORT": "99999"}))
+    e.AddRules("port", Max(65535))
+
+    s := NewVarSet(e)
+    port := s.Int("port", 8080, "")
+    err := s.Parse()
+    var rerr *RuleError
+    assert.True(errors.As(err, &rerr))
+    assert.Equal(8080, *port)
+}
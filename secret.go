@@ -0,0 +1,194 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "log/slog"
+    "path"
+    "strconv"
+)
+
+// Redacted replaces the values of secrets in all output of this package.
+const Redacted = "[REDACTED]"
+
+// DefaultSecretPatterns are the patterns initially used to detect secret
+// keys, see Env.SetSecretPatterns.
+var DefaultSecretPatterns = []string{"*PASSWORD", "*TOKEN", "*SECRET"}
+
+// Secret is a string which is redacted whenever it is printed, formatted,
+// logged or encoded as JSON. Use Value to access the actual value. Struct
+// fields of type Secret are treated as secret keys by Process.
+type Secret string
+
+func init() {
+    Register(func(str string) (Secret, error) { return Secret(str), nil }, Secret.Value)
+}
+
+// Value returns the actual value of s.
+func (s Secret) Value() string {
+    return string(s)
+}
+
+// String returns Redacted.
+func (s Secret) String() string {
+    return Redacted
+}
+
+// GoString returns a redacted Go representation of s.
+func (s Secret) GoString() string {
+    return "envconf.Secret(" + strconv.Quote(Redacted) + ")"
+}
+
+// Format writes Redacted for every verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+    if verb == 'v' && f.Flag('#') {
+        io.WriteString(f, s.GoString())
+        return
+    }
+
+    io.WriteString(f, Redacted)
+}
+
+// MarshalJSON encodes Redacted as JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+    return []byte(strconv.Quote(Redacted)), nil
+}
+
+// LogValue implements slog.LogValuer.
+func (s Secret) LogValue() slog.Value {
+    return slog.StringValue(Redacted)
+}
+
+// MarkSecret marks keys as secret. Values of secret keys are redacted in all
+// error messages, reports, usage output and dumps.
+func (e *Env) MarkSecret(keys ...string) {
+    e.mu.Lock()
+    defer e.mu.Unlock()
+
+    for _, key := range keys {
+        e.markSecretKey(e.prepareKey(key))
+    }
+}
+
+// markSecretKey marks the prepared key as secret, e.mu must be held.
+func (e *Env) markSecretKey(key string) {
+    if e.secrets == nil {
+        e.secrets = make(map[string]bool)
+    }
+
+    e.secrets[key] = true
+}
+
+// SetSecretPatterns sets the patterns identifying secret keys in addition to
+// the ones marked with MarkSecret. The patterns use the syntax of path.Match
+// and are matched against normalized and prefixed keys, so "*PASSWORD"
+// matches APP_DB_PASSWORD. Calling it without arguments disables pattern
+// matching, the initial patterns are DefaultSecretPatterns.
+func (e *Env) SetSecretPatterns(patterns ...string) error {
+    for _, p := range patterns {
+        if _, err := path.Match(p, ""); err != nil {
+            return errors.New("invalid secret pattern " + strconv.Quote(p) + ": " + err.Error())
+        }
+    }
+
+    e.mu.Lock()
+    defer e.mu.Unlock()
+
+    e.secretPatterns = append([]string(nil), patterns...)
+    return nil
+}
+
+// IsSecret reports whether key is marked as secret or matches one of the
+// secret patterns.
+func (e *Env) IsSecret(key string) bool {
+    return e.isSecretKey(e.prepareKey(key))
+}
+
+// isSecretKey is IsSecret for a prepared key.
+func (e *Env) isSecretKey(key string) bool {
+    e.mu.RLock()
+    defer e.mu.RUnlock()
+
+    if e.secrets[key] {
+        return true
+    }
+
+    for _, p := range e.secretPatterns {
+        if ok, _ := path.Match(p, key); ok {
+            return true
+        }
+    }
+
+    return false
+}
+
+// redact returns value, or Redacted if the prepared key is secret.
+func (e *Env) redact(key, value string) string {
+    if e.isSecretKey(key) {
+        return Redacted
+    }
+
+    return value
+}
+
+// redactExample returns the example or default value, or Redacted if it is
+// not empty and the prepared key is secret.
+func (e *Env) redactExample(key, example string) string {
+    if example == "" {
+        return ""
+    }
+
+    return e.redact(key, example)
+}
+
+// parseError returns a *ParseError for the prepared key, with the value
+// and the underlying error redacted if the key is secret.
+func (e *Env) parseError(key, value, typ string, err error) *ParseError {
+    secret := e.isSecretKey(key)
+    if secret {
+        value = Redacted
+        err = redactError(err)
+    }
+
+    return &ParseError{Key: key, Value: value, Type: typ, Err: err, secret: secret}
+}
+
+// redactError returns an error describing err without the value it failed
+// on. A *strconv.NumError is copied with Num redacted, so that it still
+// matches strconv.ErrSyntax and strconv.ErrRange.
+func redactError(err error) error {
+    var nerr *strconv.NumError
+    if errors.As(err, &nerr) {
+        return &strconv.NumError{Func: nerr.Func, Num: Redacted, Err: nerr.Err}
+    }
+
+    return errors.New("invalid value")
+}
+
+// Dump writes all variables of the Source starting with the prefix of e in
+// .env syntax to w, with the values of secret keys redacted. The Source must
+// implement Lister.
+func (e *Env) Dump(w io.Writer) error {
+    lister, ok := e.source.(Lister)
+    if !ok {
+        return errors.New("Source does not support listing environment variables")
+    }
+
+    for _, key := range lister.Keys() {
+        if len(key) < len(e.prefix) || key[:len(e.prefix)] != e.prefix {
+            continue
+        }
+
+        value, _ := e.source.Lookup(key)
+        if _, err := io.WriteString(w, key+"="+quoteDotenv(e.redact(key, value))+"\n"); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
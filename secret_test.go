@@ -0,0 +1,188 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log/slog"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSecret(t *testing.T) {
+    assert := assert.New(t)
+
+    s := Secret("hunter2")
+    assert.Equal("hunter2", s.Value())
+    assert.Equal(Redacted, s.String())
+
+    for _, format := range []string{"%v", "%s", "%q", "%x", "%d", "%+v", "%10s"} {
+        assert.NotContains(fmt.Sprintf(format, s), "hunter2", format)
+    }
+    assert.Equal(`envconf.Secret("[REDACTED]")`, fmt.Sprintf("%#v", s))
+    assert.NotContains(fmt.Sprint(struct{ Password Secret }{s}), "hunter2")
+    assert.NotContains(fmt.Sprintf("%#v", struct{ Password Secret }{s}), "hunter2")
+
+    b, err := json.Marshal(map[string]Secret{"password": s})
+    assert.NoError(err)
+    assert.Equal(`{"password":"[REDACTED]"}`, string(b))
+
+    var buf bytes.Buffer
+    slog.New(slog.NewTextHandler(&buf, nil)).Info("login", "password", s)
+    assert.NotContains(buf.String(), "hunter2")
+    assert.Contains(buf.String(), Redacted)
+}
+
+func TestSecretAccessors(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    SetIn(e, "db password", Secret("hunter2"))
+    assert.Equal("hunter2", e.MustGetString("db password"))
+    assert.Equal(Secret("hunter2"), MustGetFrom[Secret](e, "db password"))
+}
+
+func TestIsSecret(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    assert.True(e.IsSecret("db password"))
+    assert.True(e.IsSecret("api_token"))
+    assert.True(e.IsSecret("client secret"))
+    assert.False(e.IsSecret("port"))
+
+    e.MarkSecret("dsn")
+    assert.True(e.IsSecret("dsn"))
+    assert.True(e.isSecretKey("APP_DSN"))
+
+    assert.NoError(e.SetSecretPatterns("*_KEY"))
+    assert.True(e.IsSecret("api key"))
+    assert.False(e.IsSecret("db password"))
+    assert.True(e.IsSecret("dsn"))
+
+    assert.NoError(e.SetSecretPatterns())
+    assert.False(e.IsSecret("api key"))
+
+    assert.Error(e.SetSecretPatterns("[invalid"))
+}
+
+func TestSecretRedaction(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_PASSWORD": "hunter2",
+        "APP_API_TOKEN":   "abc123def",
+        "APP_TIMEOUT":     "soon",
+    }))
+    e.MarkSecret("timeout")
+
+    _, err := e.LookupInt("db password")
+    assert.NotContains(err.Error(), "hunter2")
+    assert.Contains(err.Error(), "invalid syntax")
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal(Redacted, perr.Value)
+        assert.NotContains(errors.Unwrap(err).Error(), "hunter2")
+    }
+    var nerr *strconv.NumError
+    if assert.True(errors.As(err, &nerr)) {
+        assert.Equal(Redacted, nerr.Num)
+    }
+    assert.True(errors.Is(err, strconv.ErrSyntax))
+
+    _, err = e.LookupDuration("timeout")
+    assert.NotContains(err.Error(), "soon")
+    assert.NotContains(errors.Unwrap(err).Error(), "soon")
+
+    err = e.NewValidator().Require("api token", "int", "").Require("timeout", "time.Duration", "").Validate()
+    var verr *ValidationError
+    if assert.True(errors.As(err, &verr)) {
+        assert.NotContains(err.Error(), "abc123def")
+        assert.NotContains(err.Error(), "soon")
+        assert.NotContains(verr.Report(), "abc123def")
+        assert.NotContains(verr.Report(), "soon")
+    }
+
+    func() {
+        defer func() {
+            r := recover()
+            assert.NotNil(r)
+            assert.NotContains(fmt.Sprint(r), "abc123def")
+        }()
+        e.MustGetUInt("api token")
+    }()
+}
+
+func TestProcessSecret(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        Password Secret `env:"password"`
+        DSN      string `env:"dsn" secret:"true"`
+        Pin      int    `env:"pin" secret:"true"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PASSWORD": "hunter2",
+        "APP_DSN":      "postgres://user:pw@host",
+        "APP_PIN":      "12x4",
+    }))
+    assert.NoError(e.SetSecretPatterns())
+
+    err := e.Process(&cfg)
+    assert.Error(err)
+    assert.NotContains(err.Error(), "12x4")
+
+    assert.Equal("hunter2", cfg.Password.Value())
+    assert.True(e.IsSecret("password"))
+    assert.True(e.IsSecret("dsn"))
+    assert.True(e.IsSecret("pin"))
+    assert.NotContains(fmt.Sprintf("%+v", cfg), "hunter2")
+
+    var weak struct {
+        DBPassword Secret `default:"changeme" minlen:"20"`
+        APIToken   string `required:"true" example:"tok-123"`
+    }
+    e = New("app")
+    e.SetSource(NewMapSource(nil))
+
+    var verr *ValidationError
+    if assert.True(errors.As(e.Process(&weak), &verr)) && assert.Len(verr.Problems, 2) {
+        assert.Equal(Redacted, verr.Problems[0].Example)
+        assert.Equal(Redacted, verr.Problems[1].Example)
+        assert.NotContains(verr.Report(), "changeme")
+        assert.NotContains(verr.Report(), "tok-123")
+    }
+}
+
+func TestDump(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_DB_PASSWORD": "hunter2",
+        "APP_PORT":        "8080",
+        "OTHER":           "x",
+    }))
+
+    var buf bytes.Buffer
+    assert.NoError(e.Dump(&buf))
+    assert.Equal("APP_DB_PASSWORD=\"[REDACTED]\"\nAPP_PORT=\"8080\"\n", buf.String())
+
+    e.SetSource(LookupFunc(func(string) (string, bool) { return "", false }))
+    assert.Error(e.Dump(&buf))
+
+    assert.False(strings.Contains(buf.String(), "hunter2"))
+}
@@ -0,0 +1,393 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "strings"
+    "time"
+)
+
+// ListOptions controls how the Slice and Map functions split and join values.
+//
+// Elements may be enclosed in double quotes or contain backslash escapes,
+// so that "a,b",c\,d is split into the elements a,b and c,d. Whitespace
+// inside quotes is always preserved. Map entries are list elements which
+// are split at the first KeyValueSeparator outside of quotes, so that
+// "a=b"=c has the key a=b; key and value are unquoted separately.
+type ListOptions struct {
+    Separator         string // separates elements, "," if empty
+    KeyValueSeparator string // separates map keys from values, "=" if empty
+    TrimSpace         bool   // trims whitespace around unquoted elements
+    DropEmpty         bool   // drops empty elements
+}
+
+var defaultListOptions = ListOptions{Separator: ",", KeyValueSeparator: "=", TrimSpace: true}
+
+// GetListOptions returns the options used to split and join lists.
+func (e *Env) GetListOptions() ListOptions {
+    return e.list
+}
+
+// SetListOptions sets the options used to split and join lists.
+func (e *Env) SetListOptions(o ListOptions) {
+    if o.Separator == "" {
+        o.Separator = defaultListOptions.Separator
+    }
+    if o.KeyValueSeparator == "" {
+        o.KeyValueSeparator = defaultListOptions.KeyValueSeparator
+    }
+
+    e.list = o
+}
+
+// splitList splits str into its elements. An empty string has no elements.
+func splitList(str string, o ListOptions) ([]string, error) {
+    if str == "" {
+        return nil, nil
+    }
+
+    raws, err := splitUnquoted(str, o.Separator, -1)
+    if err != nil {
+        return nil, err
+    }
+
+    var elems []string
+    for _, raw := range raws {
+        elem, quoted := unquoteElem(raw, o.TrimSpace)
+        if elem != "" || quoted || !o.DropEmpty {
+            elems = append(elems, elem)
+        }
+    }
+
+    return elems, nil
+}
+
+// splitUnquoted splits str at each sep which is neither quoted nor escaped,
+// keeping quotes and escapes. At most n parts are returned, all if n < 0.
+func splitUnquoted(str, sep string, n int) ([]string, error) {
+    var parts []string
+    inside := false // currently inside quotes
+    start := 0
+    for i := 0; i < len(str); i++ {
+        switch {
+        case str[i] == '\\' && i+1 < len(str):
+            i++
+        case str[i] == '"':
+            inside = !inside
+        case !inside && (n < 0 || len(parts) < n-1) && strings.HasPrefix(str[i:], sep):
+            parts = append(parts, str[start:i])
+            i += len(sep) - 1
+            start = i + 1
+        }
+    }
+
+    if inside {
+        return nil, errors.New("unterminated quote")
+    }
+
+    return append(parts, str[start:]), nil
+}
+
+// unquoteElem removes quotes and escapes from the element raw and, if trim
+// is set, whitespace outside of quotes around it. It reports whether raw
+// contains a quoted part.
+func unquoteElem(raw string, trim bool) (string, bool) {
+    var (
+        b      strings.Builder
+        quoted bool // raw contains a quoted part
+        inside bool // currently inside quotes
+        pend   int  // length of b up to the end of the last quoted part
+    )
+
+    for i := 0; i < len(raw); i++ {
+        c := raw[i]
+        switch {
+        case c == '\\' && i+1 < len(raw):
+            i++
+            b.WriteByte(raw[i])
+            pend = b.Len()
+        case c == '"':
+            inside = !inside
+            quoted = true
+            pend = b.Len()
+        case !inside && trim && b.Len() == 0 && !quoted && isSpace(rune(c)):
+            // skip leading whitespace
+        default:
+            b.WriteByte(c)
+            if inside {
+                pend = b.Len()
+            }
+        }
+    }
+
+    elem := b.String()
+    if trim {
+        elem = elem[:pend] + strings.TrimRightFunc(elem[pend:], isSpace)
+    }
+
+    return elem, quoted
+}
+
+func isSpace(r rune) bool {
+    return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// joinList joins elems so that splitList returns them again.
+func joinList(elems []string, o ListOptions) string {
+    quoted := make([]string, len(elems))
+    for i, elem := range elems {
+        quoted[i] = quoteElem(elem, o, o.Separator)
+    }
+
+    return strings.Join(quoted, o.Separator)
+}
+
+// quoteElem quotes elem if it is empty, contains one of the separators seps,
+// quotes or backslashes, or would lose surrounding whitespace.
+func quoteElem(elem string, o ListOptions, seps ...string) string {
+    quote := elem == "" || strings.ContainsAny(elem, "\"\\") ||
+        (o.TrimSpace && strings.TrimSpace(elem) != elem)
+    for _, sep := range seps {
+        quote = quote || strings.Contains(elem, sep)
+    }
+    if !quote {
+        return elem
+    }
+
+    return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(elem) + "\""
+}
+
+// SetDefaultStringSlice sets the environment if it is not already set.
+func (e *Env) SetDefaultStringSlice(key string, values []string) {
+    DefaultIn(e, key, values)
+}
+
+// SetStringSlice sets the environment to the joined list of values.
+func (e *Env) SetStringSlice(key string, values []string) {
+    SetIn(e, key, values)
+}
+
+// LookupStringSlice returns the environment parsed as list of string.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupStringSlice(key string) (values []string, err error) {
+    return GetFrom[[]string](e, key)
+}
+
+// GetStringSlice returns the environment parsed as list of string.
+func (e *Env) GetStringSlice(key string) (values []string, ok bool) {
+    values, err := e.LookupStringSlice(key)
+    return values, logError(err)
+}
+
+// MustGetStringSlice returns the environment variable parsed as list of
+// string if possible, otherwise it panics.
+func (e *Env) MustGetStringSlice(key string) (values []string) {
+    return MustGetFrom[[]string](e, key)
+}
+
+// SetDefaultBoolSlice sets the environment if it is not already set.
+func (e *Env) SetDefaultBoolSlice(key string, values []bool) {
+    DefaultIn(e, key, values)
+}
+
+// SetBoolSlice sets the environment to the joined list of values.
+func (e *Env) SetBoolSlice(key string, values []bool) {
+    SetIn(e, key, values)
+}
+
+// LookupBoolSlice returns the environment parsed as list of bool.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupBoolSlice(key string) (values []bool, err error) {
+    return GetFrom[[]bool](e, key)
+}
+
+// GetBoolSlice returns the environment parsed as list of bool.
+func (e *Env) GetBoolSlice(key string) (values []bool, ok bool) {
+    values, err := e.LookupBoolSlice(key)
+    return values, logError(err)
+}
+
+// MustGetBoolSlice returns the environment variable parsed as list of
+// bool if possible, otherwise it panics.
+func (e *Env) MustGetBoolSlice(key string) (values []bool) {
+    return MustGetFrom[[]bool](e, key)
+}
+
+// SetDefaultDurationSlice sets the environment if it is not already set.
+func (e *Env) SetDefaultDurationSlice(key string, values []time.Duration) {
+    DefaultIn(e, key, values)
+}
+
+// SetDurationSlice sets the environment to the joined list of values.
+func (e *Env) SetDurationSlice(key string, values []time.Duration) {
+    SetIn(e, key, values)
+}
+
+// LookupDurationSlice returns the environment parsed as list of time.Duration.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupDurationSlice(key string) (values []time.Duration, err error) {
+    return GetFrom[[]time.Duration](e, key)
+}
+
+// GetDurationSlice returns the environment parsed as list of time.Duration.
+func (e *Env) GetDurationSlice(key string) (values []time.Duration, ok bool) {
+    values, err := e.LookupDurationSlice(key)
+    return values, logError(err)
+}
+
+// MustGetDurationSlice returns the environment variable parsed as list of
+// time.Duration if possible, otherwise it panics.
+func (e *Env) MustGetDurationSlice(key string) (values []time.Duration) {
+    return MustGetFrom[[]time.Duration](e, key)
+}
+
+// SetDefaultFloat64Slice sets the environment if it is not already set.
+func (e *Env) SetDefaultFloat64Slice(key string, values []float64) {
+    DefaultIn(e, key, values)
+}
+
+// SetFloat64Slice sets the environment to the joined list of values.
+func (e *Env) SetFloat64Slice(key string, values []float64) {
+    SetIn(e, key, values)
+}
+
+// LookupFloat64Slice returns the environment parsed as list of float64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupFloat64Slice(key string) (values []float64, err error) {
+    return GetFrom[[]float64](e, key)
+}
+
+// GetFloat64Slice returns the environment parsed as list of float64.
+func (e *Env) GetFloat64Slice(key string) (values []float64, ok bool) {
+    values, err := e.LookupFloat64Slice(key)
+    return values, logError(err)
+}
+
+// MustGetFloat64Slice returns the environment variable parsed as list of
+// float64 if possible, otherwise it panics.
+func (e *Env) MustGetFloat64Slice(key string) (values []float64) {
+    return MustGetFrom[[]float64](e, key)
+}
+
+// SetDefaultIntSlice sets the environment if it is not already set.
+func (e *Env) SetDefaultIntSlice(key string, values []int) {
+    DefaultIn(e, key, values)
+}
+
+// SetIntSlice sets the environment to the joined list of values.
+func (e *Env) SetIntSlice(key string, values []int) {
+    SetIn(e, key, values)
+}
+
+// LookupIntSlice returns the environment parsed as list of int.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupIntSlice(key string) (values []int, err error) {
+    return GetFrom[[]int](e, key)
+}
+
+// GetIntSlice returns the environment parsed as list of int.
+func (e *Env) GetIntSlice(key string) (values []int, ok bool) {
+    values, err := e.LookupIntSlice(key)
+    return values, logError(err)
+}
+
+// MustGetIntSlice returns the environment variable parsed as list of
+// int if possible, otherwise it panics.
+func (e *Env) MustGetIntSlice(key string) (values []int) {
+    return MustGetFrom[[]int](e, key)
+}
+
+// SetDefaultInt64Slice sets the environment if it is not already set.
+func (e *Env) SetDefaultInt64Slice(key string, values []int64) {
+    DefaultIn(e, key, values)
+}
+
+// SetInt64Slice sets the environment to the joined list of values.
+func (e *Env) SetInt64Slice(key string, values []int64) {
+    SetIn(e, key, values)
+}
+
+// LookupInt64Slice returns the environment parsed as list of int64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupInt64Slice(key string) (values []int64, err error) {
+    return GetFrom[[]int64](e, key)
+}
+
+// GetInt64Slice returns the environment parsed as list of int64.
+func (e *Env) GetInt64Slice(key string) (values []int64, ok bool) {
+    values, err := e.LookupInt64Slice(key)
+    return values, logError(err)
+}
+
+// MustGetInt64Slice returns the environment variable parsed as list of
+// int64 if possible, otherwise it panics.
+func (e *Env) MustGetInt64Slice(key string) (values []int64) {
+    return MustGetFrom[[]int64](e, key)
+}
+
+// SetDefaultUIntSlice sets the environment if it is not already set.
+func (e *Env) SetDefaultUIntSlice(key string, values []uint) {
+    DefaultIn(e, key, values)
+}
+
+// SetUIntSlice sets the environment to the joined list of values.
+func (e *Env) SetUIntSlice(key string, values []uint) {
+    SetIn(e, key, values)
+}
+
+// LookupUIntSlice returns the environment parsed as list of uint.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupUIntSlice(key string) (values []uint, err error) {
+    return GetFrom[[]uint](e, key)
+}
+
+// GetUIntSlice returns the environment parsed as list of uint.
+func (e *Env) GetUIntSlice(key string) (values []uint, ok bool) {
+    values, err := e.LookupUIntSlice(key)
+    return values, logError(err)
+}
+
+// MustGetUIntSlice returns the environment variable parsed as list of
+// uint if possible, otherwise it panics.
+func (e *Env) MustGetUIntSlice(key string) (values []uint) {
+    return MustGetFrom[[]uint](e, key)
+}
+
+// SetDefaultUInt64Slice sets the environment if it is not already set.
+func (e *Env) SetDefaultUInt64Slice(key string, values []uint64) {
+    DefaultIn(e, key, values)
+}
+
+// SetUInt64Slice sets the environment to the joined list of values.
+func (e *Env) SetUInt64Slice(key string, values []uint64) {
+    SetIn(e, key, values)
+}
+
+// LookupUInt64Slice returns the environment parsed as list of uint64.
+// The error matches ErrNotSet if the environment does not exist or is a
+// *ParseError if it could not be parsed.
+func (e *Env) LookupUInt64Slice(key string) (values []uint64, err error) {
+    return GetFrom[[]uint64](e, key)
+}
+
+// GetUInt64Slice returns the environment parsed as list of uint64.
+func (e *Env) GetUInt64Slice(key string) (values []uint64, ok bool) {
+    values, err := e.LookupUInt64Slice(key)
+    return values, logError(err)
+}
+
+// MustGetUInt64Slice returns the environment variable parsed as list of
+// uint64 if possible, otherwise it panics.
+func (e *Env) MustGetUInt64Slice(key string) (values []uint64) {
+    return MustGetFrom[[]uint64](e, key)
+}
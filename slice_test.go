@@ -0,0 +1,168 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestSplitList(t *testing.T) {
+    assert := assert.New(t)
+
+    o := defaultListOptions
+    for in, out := range map[string][]string{
+        "":                   nil,
+        "a":                  {"a"},
+        "a,b,c":              {"a", "b", "c"},
+        " a , b ,c ":         {"a", "b", "c"},
+        "a,,b":               {"a", "", "b"},
+        ",":                  {"", ""},
+        `"a,b",c`:            {"a,b", "c"},
+        `a\,b,c`:             {"a,b", "c"},
+        `" a ", b`:           {" a ", "b"},
+        `"say \"hi\"",x`:     {`say "hi"`, "x"},
+        `pre"fix,"post`:      {"prefix,post"},
+        `""`:                 {""},
+        `\\,x`:               {`\`, "x"},
+    } {
+        elems, err := splitList(in, o)
+        assert.NoError(err, in)
+        assert.Equal(out, elems, in)
+    }
+
+    _, err := splitList(`"open,x`, o)
+    assert.Error(err)
+
+    o.DropEmpty = true
+    elems, err := splitList("a,, ,b,", o)
+    assert.NoError(err)
+    assert.Equal([]string{"a", "b"}, elems)
+    elems, err = splitList(`a,"",b`, o)
+    assert.NoError(err)
+    assert.Equal([]string{"a", "", "b"}, elems)
+
+    o = ListOptions{Separator: " :: "}
+    elems, err = splitList(" a :: b ", o)
+    assert.NoError(err)
+    assert.Equal([]string{" a", "b "}, elems)
+}
+
+func TestJoinList(t *testing.T) {
+    assert := assert.New(t)
+
+    for _, o := range []ListOptions{defaultListOptions, {Separator: ";"}, {Separator: ",", DropEmpty: true, TrimSpace: true}} {
+        for _, elems := range [][]string{
+            {"a", "b"},
+            {"a,b", "c;d"},
+            {" padded ", "x"},
+            {`quote"d`, `back\slash`},
+            {""},
+            {"a", "", "b"},
+        } {
+            out, err := splitList(joinList(elems, o), o)
+            assert.NoError(err)
+            assert.Equal(elems, out, joinList(elems, o))
+        }
+    }
+
+    assert.Equal("a,b", joinList([]string{"a", "b"}, defaultListOptions))
+    assert.Equal(`"a,b",c`, joinList([]string{"a,b", "c"}, defaultListOptions))
+    assert.Equal(`a,"",b`, joinList([]string{"a", "", "b"}, defaultListOptions))
+}
+
+func TestSlices(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    _, err := e.LookupStringSlice("list")
+    assert.True(errors.Is(err, ErrNotSet))
+    _, ok := e.GetIntSlice("list")
+    assert.False(ok)
+    assert.Panics(func() { e.MustGetDurationSlice("list") })
+
+    e.SetDefaultStringSlice("list", []string{"a", "b,c"})
+    assert.Equal("a,\"b,c\"", e.MustGetString("list"))
+    assert.Equal([]string{"a", "b,c"}, e.MustGetStringSlice("list"))
+    e.SetDefaultStringSlice("list", []string{"x"})
+    assert.Equal([]string{"a", "b,c"}, e.MustGetStringSlice("list"))
+
+    e.SetBoolSlice("list", []bool{true, false})
+    assert.Equal([]bool{true, false}, e.MustGetBoolSlice("list"))
+    e.SetIntSlice("list", []int{1, -2, 3})
+    assert.Equal([]int{1, -2, 3}, e.MustGetIntSlice("list"))
+    e.SetInt64Slice("list", []int64{1 << 40})
+    assert.Equal([]int64{1 << 40}, e.MustGetInt64Slice("list"))
+    e.SetUIntSlice("list", []uint{4, 5})
+    assert.Equal([]uint{4, 5}, e.MustGetUIntSlice("list"))
+    e.SetUInt64Slice("list", []uint64{1 << 63})
+    assert.Equal([]uint64{1 << 63}, e.MustGetUInt64Slice("list"))
+    e.SetFloat64Slice("list", []float64{0.5, -1})
+    assert.Equal([]float64{0.5, -1}, e.MustGetFloat64Slice("list"))
+    e.SetDurationSlice("list", []time.Duration{time.Second, time.Hour})
+    assert.Equal([]time.Duration{time.Second, time.Hour}, e.MustGetDurationSlice("list"))
+
+    e.SetString("list", "1, 2, x")
+    _, err = e.LookupIntSlice("list")
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("[]int", perr.Type)
+        assert.Equal("1, 2, x", perr.Value)
+        assert.Equal("APP_LIST", perr.Key)
+    }
+    _, ok = e.GetUIntSlice("list")
+    assert.False(ok)
+    assert.Panics(func() { e.MustGetInt64Slice("list") })
+
+    e.SetString("list", "")
+    v, err := e.LookupStringSlice("list")
+    assert.NoError(err)
+    assert.Empty(v)
+
+    e.SetListOptions(ListOptions{Separator: ":", DropEmpty: true})
+    e.SetString("list", "/bin::/usr/bin")
+    assert.Equal([]string{"/bin", "/usr/bin"}, e.MustGetStringSlice("list"))
+    assert.Equal(ListOptions{Separator: ":", KeyValueSeparator: "=", DropEmpty: true}, e.GetListOptions())
+
+    e.SetStringSlice("list", []string{"a", "", "b"})
+    assert.Equal([]string{"a", "", "b"}, e.MustGetStringSlice("list"))
+
+    e.SetListOptions(ListOptions{})
+    assert.Equal(",", e.GetListOptions().Separator)
+}
+
+func TestProcessSlices(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        Hosts    []string        `default:"a, b"`
+        Ports    []int           `env:"ports"`
+        Timeouts []time.Duration `env:"timeouts" default:"1s,2s"`
+        Nested   [][]string      `env:"nested"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_PORTS": "80,443"}))
+
+    assert.NoError(e.Process(&cfg))
+    assert.Equal([]string{"a", "b"}, cfg.Hosts)
+    assert.Equal([]int{80, 443}, cfg.Ports)
+    assert.Equal([]time.Duration{time.Second, 2 * time.Second}, cfg.Timeouts)
+
+    e.SetString("ports", "80,http")
+    e.SetString("nested", "a")
+    err := e.Process(&cfg)
+    var verr *ValidationError
+    if assert.True(errors.As(err, &verr)) {
+        assert.Len(verr.Problems, 2)
+        assert.Equal("APP_PORTS", verr.Problems[0].Key)
+        assert.Equal("[]int", verr.Problems[0].Type)
+    }
+}
@@ -0,0 +1,189 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "os"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// Source is the interface that wraps the Lookup method.
+//
+// Lookup returns the value stored under the given key, which has already been
+// normalized and prefixed by the Env. The boolean reports whether the key
+// is present.
+type Source interface {
+    Lookup(key string) (value string, ok bool)
+}
+
+// Setter is implemented by sources which can store values.
+type Setter interface {
+    Set(key, value string) error
+}
+
+// Unsetter is implemented by sources which can remove values.
+type Unsetter interface {
+    Unset(key string) error
+}
+
+// Lister is implemented by sources which can enumerate their keys.
+type Lister interface {
+    Keys() []string
+}
+
+// LookupFunc is an adapter to allow the use of an ordinary function as a
+// read-only Source.
+type LookupFunc func(key string) (string, bool)
+
+// Lookup calls f(key).
+func (f LookupFunc) Lookup(key string) (string, bool) {
+    return f(key)
+}
+
+// OSEnv is the Source backed by the environment of the current process.
+type OSEnv struct{}
+
+// Lookup calls os.LookupEnv.
+func (OSEnv) Lookup(key string) (string, bool) {
+    return os.LookupEnv(key)
+}
+
+// Set calls os.Setenv.
+func (OSEnv) Set(key, value string) error {
+    return os.Setenv(key, value)
+}
+
+// Unset calls os.Unsetenv.
+func (OSEnv) Unset(key string) error {
+    return os.Unsetenv(key)
+}
+
+// Keys returns the sorted keys of os.Environ.
+func (OSEnv) Keys() []string {
+    var keys []string
+    for _, kv := range os.Environ() {
+        if i := strings.Index(kv, "="); i > 0 {
+            keys = append(keys, kv[:i])
+        }
+    }
+
+    sort.Strings(keys)
+    return keys
+}
+
+// MapSource is an in-memory Source which is safe for concurrent use.
+type MapSource struct {
+    mu     sync.RWMutex
+    values map[string]string
+}
+
+// NewMapSource returns a MapSource initialized with a copy of values.
+func NewMapSource(values map[string]string) *MapSource {
+    m := &MapSource{values: make(map[string]string, len(values))}
+    for k, v := range values {
+        m.values[k] = v
+    }
+
+    return m
+}
+
+// Lookup returns the value stored under key.
+func (m *MapSource) Lookup(key string) (string, bool) {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    v, ok := m.values[key]
+    return v, ok
+}
+
+// Set stores value under key.
+func (m *MapSource) Set(key, value string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if m.values == nil {
+        m.values = make(map[string]string)
+    }
+    m.values[key] = value
+    return nil
+}
+
+// Unset removes key.
+func (m *MapSource) Unset(key string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    delete(m.values, key)
+    return nil
+}
+
+// Keys returns the sorted keys of m.
+func (m *MapSource) Keys() []string {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+
+    keys := make([]string, 0, len(m.values))
+    for k := range m.values {
+        keys = append(keys, k)
+    }
+
+    sort.Strings(keys)
+    return keys
+}
+
+// FileSource is a read-only Source holding the values read from a file. It
+// remembers the line each value was read from, see Originator.
+type FileSource struct {
+    name   string
+    values map[string]fileValue
+}
+
+type fileValue struct {
+    value string
+    line  int
+}
+
+func newFileSource(name string) *FileSource {
+    return &FileSource{name: name, values: make(map[string]fileValue)}
+}
+
+// set stores value under key, a later value for the same key wins.
+func (s *FileSource) set(key, value string, line int) {
+    s.values[key] = fileValue{value: value, line: line}
+}
+
+// Name returns the name of the file.
+func (s *FileSource) Name() string {
+    return s.name
+}
+
+// Lookup returns the value stored under key.
+func (s *FileSource) Lookup(key string) (string, bool) {
+    v, ok := s.values[key]
+    return v.value, ok
+}
+
+// Keys returns the sorted keys of s.
+func (s *FileSource) Keys() []string {
+    keys := make([]string, 0, len(s.values))
+    for k := range s.values {
+        keys = append(keys, k)
+    }
+
+    sort.Strings(keys)
+    return keys
+}
+
+// Origin returns the file and line key was read from.
+func (s *FileSource) Origin(key string) (Origin, bool) {
+    v, ok := s.values[key]
+    if !ok {
+        return Origin{}, false
+    }
+
+    return Origin{File: s.name, Line: v.line}, true
+}
@@ -0,0 +1,85 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "os"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestOSEnv(t *testing.T) {
+    assert := assert.New(t)
+
+    var s OSEnv
+    assert.NoError(s.Set("ENVCONF_SOURCE_TEST", "42"))
+    v, ok := s.Lookup("ENVCONF_SOURCE_TEST")
+    assert.True(ok)
+    assert.Equal("42", v)
+    assert.Equal("42", os.Getenv("ENVCONF_SOURCE_TEST"))
+    assert.Contains(s.Keys(), "ENVCONF_SOURCE_TEST")
+
+    assert.NoError(s.Unset("ENVCONF_SOURCE_TEST"))
+    _, ok = s.Lookup("ENVCONF_SOURCE_TEST")
+    assert.False(ok)
+    assert.NotContains(s.Keys(), "ENVCONF_SOURCE_TEST")
+}
+
+func TestMapSource(t *testing.T) {
+    assert := assert.New(t)
+
+    values := map[string]string{"B": "2", "A": "1"}
+    m := NewMapSource(values)
+    values["C"] = "3"
+
+    assert.Equal([]string{"A", "B"}, m.Keys())
+    v, ok := m.Lookup("A")
+    assert.True(ok)
+    assert.Equal("1", v)
+
+    assert.NoError(m.Set("C", ""))
+    v, ok = m.Lookup("C")
+    assert.True(ok)
+    assert.Empty(v)
+
+    assert.NoError(m.Unset("A"))
+    _, ok = m.Lookup("A")
+    assert.False(ok)
+    assert.Equal([]string{"B", "C"}, m.Keys())
+
+    var zero MapSource
+    assert.NoError(zero.Set("A", "1"))
+    assert.Equal([]string{"A"}, zero.Keys())
+}
+
+func TestEnvSource(t *testing.T) {
+    assert := assert.New(t)
+
+    m := NewMapSource(map[string]string{"APP_TIMEOUT": "3s"})
+    e := New("app")
+    e.SetSource(m)
+    assert.Equal(m, e.GetSource())
+
+    assert.Equal(3*time.Second, e.MustGetDuration("timeout"))
+
+    e.SetInt("envconf source port", 8080)
+    assert.Equal(8080, e.MustGetInt("envconf source port"))
+    assert.False(New("app").IssetKey("envconf source port"))
+    assert.Equal([]string{"APP_ENVCONF_SOURCE_PORT", "APP_TIMEOUT"}, m.Keys())
+
+    e.UnsetKey("timeout")
+    assert.False(e.IssetKey("timeout"))
+
+    e.SetSource(nil)
+    assert.Equal(OSEnv{}, e.GetSource())
+
+    // Read-only sources ignore writes
+    e.SetSource(LookupFunc(func(key string) (string, bool) { return "", false }))
+    e.SetString("envconf source port", "1")
+    e.UnsetKey("envconf source port")
+    assert.False(e.IssetKey("envconf source port"))
+}
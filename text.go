@@ -0,0 +1,85 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "encoding"
+    "reflect"
+)
+
+var (
+    textUnmarshalerType = typeOf[encoding.TextUnmarshaler]()
+    textMarshalerType   = typeOf[encoding.TextMarshaler]()
+)
+
+// textConverter returns a converter for types implementing
+// encoding.TextUnmarshaler or encoding.TextMarshaler, with either a value
+// or a pointer receiver.
+func textConverter(t reflect.Type) (converter, bool) {
+    var c converter
+
+    switch {
+    case t.Kind() == reflect.Ptr && t.Implements(textUnmarshalerType):
+        c.parse = func(str string) (interface{}, error) {
+            v := reflect.New(t.Elem())
+            err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str))
+            return v.Interface(), err
+        }
+    case reflect.PtrTo(t).Implements(textUnmarshalerType):
+        c.parse = func(str string) (interface{}, error) {
+            v := reflect.New(t)
+            err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str))
+            return v.Elem().Interface(), err
+        }
+    }
+
+    switch {
+    case t.Implements(textMarshalerType):
+        c.format = func(value interface{}) (string, error) {
+            if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+                return "", nil
+            }
+
+            b, err := value.(encoding.TextMarshaler).MarshalText()
+            return string(b), err
+        }
+    case reflect.PtrTo(t).Implements(textMarshalerType):
+        c.format = func(value interface{}) (string, error) {
+            v := reflect.New(t)
+            v.Elem().Set(reflect.ValueOf(value))
+            b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+            return string(b), err
+        }
+    }
+
+    return c, c.parse != nil || c.format != nil
+}
+
+// GetText parses the environment variable into v using its UnmarshalText
+// method. The error matches ErrNotSet if the environment does not exist or
+// is a *ParseError if it could not be parsed.
+func (e *Env) GetText(key string, v encoding.TextUnmarshaler) error {
+    str, err := e.lookup(key)
+    if err != nil {
+        return err
+    }
+
+    if err := v.UnmarshalText([]byte(str)); err != nil {
+        return e.parseError(e.prepareKey(key), str, reflect.TypeOf(v).String(), err)
+    }
+
+    return nil
+}
+
+// SetText sets the environment to the result of the MarshalText method of v.
+func (e *Env) SetText(key string, v encoding.TextMarshaler) error {
+    b, err := v.MarshalText()
+    if err != nil {
+        return err
+    }
+
+    e.SetString(key, string(b))
+    return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "log/slog"
+    "math/big"
+    "net"
+    "strconv"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type textTestColor int
+
+func (c textTestColor) MarshalText() ([]byte, error) {
+    switch c {
+    case 1:
+        return []byte("red"), nil
+    case 2:
+        return []byte("green"), nil
+    }
+    return nil, errors.New("invalid color")
+}
+
+func (c *textTestColor) UnmarshalText(b []byte) error {
+    switch strings.ToLower(string(b)) {
+    case "red":
+        *c = 1
+    case "green":
+        *c = 2
+    default:
+        return errors.New("unknown color")
+    }
+    return nil
+}
+
+type textTestPriority int
+
+func (p textTestPriority) MarshalText() ([]byte, error) {
+    return []byte("P" + strconv.Itoa(int(p))), nil
+}
+
+type textTestWeight int
+
+func (w *textTestWeight) UnmarshalText(b []byte) error {
+    n, err := strconv.Atoi(strings.TrimSuffix(string(b), "kg"))
+    *w = textTestWeight(n)
+    return err
+}
+
+func TestText(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    var ip net.IP
+    assert.True(errors.Is(e.GetText("ip", &ip), ErrNotSet))
+
+    assert.NoError(e.SetText("ip", net.ParseIP("10.0.0.1")))
+    assert.Equal("10.0.0.1", e.MustGetString("ip"))
+    assert.NoError(e.GetText("ip", &ip))
+    assert.True(ip.Equal(net.ParseIP("10.0.0.1")))
+
+    e.SetString("ip", "not an ip")
+    var perr *ParseError
+    if assert.True(errors.As(e.GetText("ip", &ip), &perr)) {
+        assert.Equal("*net.IP", perr.Type)
+        assert.Equal("APP_IP", perr.Key)
+    }
+
+    assert.Error(e.SetText("color", textTestColor(7)))
+    assert.False(e.IssetKey("color"))
+}
+
+func TestTextGeneric(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    SetIn(e, "color", textTestColor(2))
+    assert.Equal("green", e.MustGetString("color"))
+    assert.Equal(textTestColor(2), MustGetFrom[textTestColor](e, "color"))
+
+    SetIn(e, "colors", []textTestColor{1, 2})
+    assert.Equal("red,green", e.MustGetString("colors"))
+    assert.Equal([]textTestColor{1, 2}, MustGetFrom[[]textTestColor](e, "colors"))
+
+    e.SetString("level", "WARN")
+    assert.Equal(slog.LevelWarn, MustGetFrom[slog.Level](e, "level"))
+    SetIn(e, "level", slog.LevelError)
+    assert.Equal("ERROR", e.MustGetString("level"))
+
+    n, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+    SetIn(e, "big", n)
+    assert.Equal("123456789012345678901234567890", e.MustGetString("big"))
+    assert.Equal(0, n.Cmp(MustGetFrom[*big.Int](e, "big")))
+    SetIn(e, "big", (*big.Int)(nil))
+    assert.Equal("", e.MustGetString("big"))
+
+    e.SetString("color", "blue")
+    _, err := GetFrom[textTestColor](e, "color")
+    var perr *ParseError
+    if assert.True(errors.As(err, &perr)) {
+        assert.Equal("envconf.textTestColor", perr.Type)
+    }
+}
+
+func TestTextHalf(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        Priority textTestPriority `env:"priority"`
+        Weight   textTestWeight   `env:"weight"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_PRIORITY": "3", "APP_WEIGHT": "80kg"}))
+
+    assert.NoError(e.Process(&cfg))
+    assert.Equal(textTestPriority(3), cfg.Priority)
+    assert.Equal(textTestWeight(80), cfg.Weight)
+
+    SetIn(e, "priority", textTestPriority(2))
+    assert.Equal("P2", e.MustGetString("priority"))
+    SetIn(e, "weight", textTestWeight(75))
+    assert.Equal("75", e.MustGetString("weight"))
+    assert.Equal(textTestWeight(75), MustGetFrom[textTestWeight](e, "weight"))
+
+    e.SetString("priority", "high")
+    assert.Error(e.Process(&cfg))
+}
+
+func TestProcessText(t *testing.T) {
+    assert := assert.New(t)
+
+    var cfg struct {
+        Listen net.IP        `env:"listen" default:"127.0.0.1"`
+        Color  textTestColor `env:"color" default:"red"`
+        Level  slog.Level    `env:"level"`
+        Max    *big.Int      `env:"max"`
+    }
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{"APP_LEVEL": "debug", "APP_MAX": "1000"}))
+
+    assert.NoError(e.Process(&cfg))
+    assert.Equal("127.0.0.1", cfg.Listen.String())
+    assert.Equal(textTestColor(1), cfg.Color)
+    assert.Equal(slog.LevelDebug, cfg.Level)
+    assert.Equal(int64(1000), cfg.Max.Int64())
+
+    e.SetString("color", "blue")
+    assert.Error(e.Process(&cfg))
+}
@@ -0,0 +1,241 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "reflect"
+    "strings"
+    "text/tabwriter"
+)
+
+// UsageFormat selects the output format of WriteUsage.
+type UsageFormat int
+
+const (
+    // UsageText is an aligned plain-text table.
+    UsageText UsageFormat = iota
+
+    // UsageMarkdown is a Markdown table, e.g. for a README.
+    UsageMarkdown
+
+    // UsageJSON is a JSON array with one object per variable.
+    UsageJSON
+)
+
+// usageRow is a single variable as shown in the usage output.
+type usageRow struct {
+    Key         string  `json:"key"`
+    Type        string  `json:"type"`
+    Default     string  `json:"default"`
+    Required    bool    `json:"required"`
+    Secret      bool    `json:"secret"`
+    Value       *string `json:"value"`
+    Description string  `json:"description"`
+}
+
+// Describe returns the variables bound to the fields of spec, which must be
+// a struct or a pointer to a struct. See Process for the supported tags.
+func (e *Env) Describe(spec interface{}) ([]*Variable, error) {
+    rt := reflect.TypeOf(spec)
+    if rt != nil && rt.Kind() == reflect.Ptr {
+        rt = rt.Elem()
+    }
+    if rt == nil || rt.Kind() != reflect.Struct {
+        return nil, errors.New("Describe requires a struct or a pointer to a struct")
+    }
+
+    return e.describeStruct(rt, ""), nil
+}
+
+func (e *Env) describeStruct(rt reflect.Type, prefix string) []*Variable {
+    return e.describeFields(rt, prefix, map[reflect.Type]bool{rt: true})
+}
+
+// describeFields describes the fields of rt. Pointers to the struct types
+// in outer, which are being described, are skipped, which ends recursive
+// types like linked lists.
+func (e *Env) describeFields(rt reflect.Type, prefix string, outer map[reflect.Type]bool) []*Variable {
+    var vars []*Variable
+    for i := 0; i < rt.NumField(); i++ {
+        field := rt.Field(i)
+        if skipField(field) {
+            continue
+        }
+
+        if sub, ok := nestedPrefix(field, prefix); ok {
+            t, _ := nestedType(field.Type)
+            if outer[t] {
+                continue
+            }
+
+            outer[t] = true
+            vars = append(vars, e.describeFields(t, sub, outer)...)
+            delete(outer, t)
+            continue
+        }
+        if isCollection(field.Type) {
+            sub := joinPrefix(joinPrefix(prefix, fieldName(field)), collectionPlaceholder(field.Type))
+            vars = append(vars, e.describeStruct(field.Type.Elem(), sub)...)
+            continue
+        }
+
+        if v, ok := e.fieldVariable(field, prefix); ok {
+            vars = append(vars, v)
+        }
+    }
+
+    return vars
+}
+
+// PrintUsage writes a plain-text table of all variables bound to the fields
+// of spec to w. See WriteUsage for details.
+func (e *Env) PrintUsage(w io.Writer, spec interface{}) error {
+    return e.WriteUsage(w, spec, UsageText)
+}
+
+// WriteUsage writes all variables bound to the fields of spec to w in the
+// given format. Each variable is listed with its prefixed key, type,
+// default, whether it is required or secret, its current value and the
+// "usage" tag as description. Values of secret keys are redacted.
+func (e *Env) WriteUsage(w io.Writer, spec interface{}, format UsageFormat) error {
+    vars, err := e.Describe(spec)
+    if err != nil {
+        return err
+    }
+
+    return e.writeUsage(w, vars, format)
+}
+
+// PrintUsage writes a plain-text table of all registered variables to w.
+// See WriteUsage for details.
+func (s *VarSet) PrintUsage(w io.Writer) error {
+    return s.WriteUsage(w, UsageText)
+}
+
+// WriteUsage writes all registered variables to w in the given format. See
+// Env.WriteUsage for details.
+func (s *VarSet) WriteUsage(w io.Writer, format UsageFormat) error {
+    return s.env.writeUsage(w, s.Variables(), format)
+}
+
+func (e *Env) writeUsage(w io.Writer, vars []*Variable, format UsageFormat) error {
+    rows := make([]usageRow, len(vars))
+    for i, v := range vars {
+        key := e.prepareKey(v.Name)
+        secret := v.Secret || e.isSecretKey(key)
+
+        rows[i] = usageRow{
+            Key:         key,
+            Type:        v.Type,
+            Default:     v.Default,
+            Required:    v.Required,
+            Secret:      secret,
+            Description: v.Usage,
+        }
+
+        if secret && v.Default != "" {
+            rows[i].Default = Redacted
+        }
+        if value, err := e.lookup(v.Name); err == nil {
+            if secret {
+                value = Redacted
+            }
+            rows[i].Value = &value
+        }
+    }
+
+    switch format {
+    case UsageText:
+        return writeUsageText(w, rows)
+    case UsageMarkdown:
+        return writeUsageMarkdown(w, rows)
+    case UsageJSON:
+        enc := json.NewEncoder(w)
+        enc.SetIndent("", "  ")
+        return enc.Encode(rows)
+    }
+
+    return fmt.Errorf("Unknown usage format %d", format)
+}
+
+func writeUsageText(w io.Writer, rows []usageRow) error {
+    tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "KEY\tTYPE\tDEFAULT\tREQUIRED\tSECRET\tVALUE\tDESCRIPTION")
+    for _, r := range rows {
+        fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Key, r.Type, r.Default,
+            yesNo(r.Required), yesNo(r.Secret), usageValue(r.Value), r.Description)
+    }
+
+    return tw.Flush()
+}
+
+func writeUsageMarkdown(w io.Writer, rows []usageRow) error {
+    var b strings.Builder
+    b.WriteString("| Key | Type | Default | Required | Secret | Value | Description |\n")
+    b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+    for _, r := range rows {
+        cells := []string{
+            markdownCode(r.Key), markdownCode(r.Type), markdownCode(r.Default),
+            yesNo(r.Required), yesNo(r.Secret), markdownCode(usageValue(r.Value)),
+            markdownEscape(r.Description),
+        }
+        b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+    }
+
+    _, err := io.WriteString(w, b.String())
+    return err
+}
+
+func yesNo(b bool) string {
+    if b {
+        return "yes"
+    }
+
+    return "no"
+}
+
+// usageValue returns the current value, or an empty string if it is not set.
+func usageValue(value *string) string {
+    if value == nil {
+        return ""
+    }
+
+    return *value
+}
+
+// markdownEscape makes s safe to use inside a Markdown table cell.
+func markdownEscape(s string) string {
+    return strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// markdownCode formats s as code span, or returns an empty string.
+func markdownCode(s string) string {
+    if s == "" {
+        return ""
+    }
+
+    fence := "`"
+    for strings.Contains(s, fence) {
+        fence += "`"
+    }
+
+    return fence + markdownEscape(s) + fence
+}
+
+// PrintUsage writes a plain-text table of all variables registered in
+// DefaultVars to w. See Env.WriteUsage for details.
+func PrintUsage(w io.Writer) error {
+    return DefaultVars.PrintUsage(w)
+}
+
+// WriteUsage writes all variables registered in DefaultVars to w in the given
+// format. See Env.WriteUsage for details.
+func WriteUsage(w io.Writer, format UsageFormat) error {
+    return DefaultVars.WriteUsage(w, format)
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "encoding/json"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type usageTestConfig struct {
+    Host     string        `default:"localhost" usage:"listen address"`
+    Port     int           `default:"8080" required:"true" usage:"listen port"`
+    Timeout  time.Duration `env:"read timeout" usage:"read | write timeout"`
+    Password Secret        `usage:"database password"`
+    Ignored  string        `env:"-"`
+    internal string
+}
+
+func newUsageTestEnv() *Env {
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PORT":     "9090",
+        "APP_PASSWORD": "hunter2",
+    }))
+
+    return e
+}
+
+func TestDescribe(t *testing.T) {
+    assert := assert.New(t)
+
+    e := newUsageTestEnv()
+    vars, err := e.Describe(usageTestConfig{})
+    assert.NoError(err)
+    assert.Len(vars, 4)
+    assert.Equal(&Variable{Name: "Port", Key: "APP_PORT", Type: "int", Default: "8080", Usage: "listen port", Required: true}, vars[1])
+    assert.Equal("APP_READ_TIMEOUT", vars[2].Key)
+    assert.True(vars[3].Secret)
+
+    _, err = e.Describe(&usageTestConfig{})
+    assert.NoError(err)
+    _, err = e.Describe(42)
+    assert.Error(err)
+    _, err = e.Describe(nil)
+    assert.Error(err)
+}
+
+func TestPrintUsage(t *testing.T) {
+    assert := assert.New(t)
+
+    var buf bytes.Buffer
+    assert.NoError(newUsageTestEnv().PrintUsage(&buf, &usageTestConfig{}))
+
+    lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+    assert.Len(lines, 5)
+    assert.Equal([]string{"KEY", "TYPE", "DEFAULT", "REQUIRED", "SECRET", "VALUE", "DESCRIPTION"}, strings.Fields(lines[0]))
+    assert.Equal([]string{"APP_HOST", "string", "localhost", "no", "no", "listen", "address"}, strings.Fields(lines[1]))
+    assert.Equal([]string{"APP_PORT", "int", "8080", "yes", "no", "9090", "listen", "port"}, strings.Fields(lines[2]))
+    assert.Contains(lines[4], Redacted)
+    assert.NotContains(buf.String(), "hunter2")
+}
+
+func TestWriteUsageMarkdown(t *testing.T) {
+    assert := assert.New(t)
+
+    var buf bytes.Buffer
+    assert.NoError(newUsageTestEnv().WriteUsage(&buf, usageTestConfig{}, UsageMarkdown))
+    assert.Equal("| Key | Type | Default | Required | Secret | Value | Description |\n"+
+        "| --- | --- | --- | --- | --- | --- | --- |\n"+
+        "| `APP_HOST` | `string` | `localhost` | no | no |  | listen address |\n"+
+        "| `APP_PORT` | `int` | `8080` | yes | no | `9090` | listen port |\n"+
+        "| `APP_READ_TIMEOUT` | `time.Duration` |  | no | no |  | read \\| write timeout |\n"+
+        "| `APP_PASSWORD` | `envconf.Secret` |  | no | yes | `[REDACTED]` | database password |\n", buf.String())
+
+    assert.Equal("``a`b``", markdownCode("a`b"))
+}
+
+func TestWriteUsageJSON(t *testing.T) {
+    assert := assert.New(t)
+
+    var buf bytes.Buffer
+    assert.NoError(newUsageTestEnv().WriteUsage(&buf, usageTestConfig{}, UsageJSON))
+
+    var rows []map[string]interface{}
+    assert.NoError(json.Unmarshal(buf.Bytes(), &rows))
+    assert.Len(rows, 4)
+    assert.Equal(map[string]interface{}{
+        "key": "APP_PORT", "type": "int", "default": "8080", "required": true,
+        "secret": false, "value": "9090", "description": "listen port",
+    }, rows[1])
+    assert.Nil(rows[0]["value"])
+    assert.Equal(Redacted, rows[3]["value"])
+
+    assert.Error(newUsageTestEnv().WriteUsage(&buf, usageTestConfig{}, UsageFormat(42)))
+}
+
+func TestVarSetPrintUsage(t *testing.T) {
+    assert := assert.New(t)
+
+    e := newUsageTestEnv()
+    s := NewVarSet(e)
+    s.Int("port", 8080, "listen port")
+    s.String("token", "", "API token")
+    s.String("api token", "defaulttok", "")
+
+    var buf bytes.Buffer
+    assert.NoError(s.PrintUsage(&buf))
+    assert.Contains(buf.String(), "APP_PORT")
+    assert.Contains(buf.String(), "listen port")
+    assert.NotContains(buf.String(), "defaulttok")
+
+    buf.Reset()
+    assert.NoError(s.WriteUsage(&buf, UsageJSON))
+    var rows []usageRow
+    assert.NoError(json.Unmarshal(buf.Bytes(), &rows))
+    assert.Len(rows, 3)
+    assert.Equal("9090", *rows[0].Value)
+    assert.True(rows[1].Secret)
+    assert.Equal("", rows[1].Default)
+    assert.Equal(Redacted, rows[2].Default)
+
+    buf.Reset()
+    assert.NoError(e.PrintUsage(&buf, struct {
+        DBPassword Secret `default:"changeme"`
+    }{}))
+    assert.Contains(buf.String(), Redacted)
+    assert.NotContains(buf.String(), "changeme")
+}
+
+func TestDescribeNested(t *testing.T) {
+    assert := assert.New(t)
+
+    vars, err := New("app").Describe(bindTestNested{})
+    assert.NoError(err)
+
+    var keys []string
+    for _, v := range vars {
+        keys = append(keys, v.Key)
+    }
+    assert.Equal([]string{"APP_LOG_LEVEL", "APP_PRIMARY_DB_HOST", "APP_PRIMARY_DB_PORT",
+        "APP_REPLICA_DB_HOST", "APP_REPLICA_DB_PORT", "APP_CACHE_TTL", "APP_HOST", "APP_PORT", "APP_URL"}, keys)
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "reflect"
+    "strconv"
+    "text/tabwriter"
+)
+
+// stderr and exit are used by the MustValidate functions and replaced in tests.
+var (
+    stderr io.Writer = os.Stderr
+    exit             = os.Exit
+)
+
+// Problem describes a single environment variable which failed validation.
+type Problem struct {
+    Key     string // normalized and prefixed key
+    Type    string // name of the expected type
+    Example string // example value, may be empty
+    Err     error  // matches ErrNotSet, is a *ParseError, a *RuleError or any other error
+}
+
+// Description returns a short human-readable description of the problem.
+func (p Problem) Description() string {
+    var rerr *RuleError
+    if errors.As(p.Err, &rerr) {
+        if errors.Is(rerr.Err, ErrNotSet) {
+            return rerr.Err.Error()
+        }
+        return "invalid value " + strconv.Quote(rerr.Value) + ": " + rerr.Err.Error()
+    }
+
+    if errors.Is(p.Err, ErrNotSet) {
+        return "not set"
+    }
+
+    var perr *ParseError
+    if errors.As(p.Err, &perr) {
+        return "invalid value " + strconv.Quote(perr.Value) + ": " + perr.reason()
+    }
+
+    return p.Err.Error()
+}
+
+// ValidationError is returned if one or more environment variables are
+// missing or malformed. It contains one Problem per variable.
+type ValidationError struct {
+    Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+    if len(e.Problems) == 1 {
+        return e.Problems[0].Err.Error()
+    }
+
+    msg := strconv.Itoa(len(e.Problems)) + " environment variables are invalid:"
+    for i, p := range e.Problems {
+        if i > 0 {
+            msg += ","
+        }
+        msg += " " + p.Key + " (" + p.Description() + ")"
+    }
+
+    return msg
+}
+
+// Unwrap returns the errors of all problems.
+func (e *ValidationError) Unwrap() []error {
+    errs := make([]error, len(e.Problems))
+    for i, p := range e.Problems {
+        errs[i] = p.Err
+    }
+
+    return errs
+}
+
+// WriteReport writes a human-readable table of all problems to w.
+func (e *ValidationError) WriteReport(w io.Writer) error {
+    tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(tw, "Invalid environment configuration:")
+    fmt.Fprintln(tw)
+    fmt.Fprintln(tw, "  KEY\tPROBLEM\tTYPE\tEXAMPLE")
+    for _, p := range e.Problems {
+        fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", p.Key, p.Description(), p.Type, p.Example)
+    }
+
+    return tw.Flush()
+}
+
+// Report returns the report written by WriteReport as string.
+func (e *ValidationError) Report() string {
+    var buf bytes.Buffer
+    e.WriteReport(&buf)
+    return buf.String()
+}
+
+// Validator checks a set of declared environment variables at once and
+// reports all problems instead of stopping at the first one.
+type Validator struct {
+    env    *Env
+    checks []check
+}
+
+type check struct {
+    key      string
+    typ      reflect.Type
+    example  string
+    required bool
+}
+
+// NewValidator returns a Validator which checks variables of e.
+func (e *Env) NewValidator() *Validator {
+    return &Validator{env: e}
+}
+
+// Require declares a variable which must be set and parseable as typ, the
+// name of a registered type such as "int", "time.Duration" or a type added
+// with Register. Require panics for any other type name. The example is
+// only used in reports.
+func (v *Validator) Require(key, typ, example string) *Validator {
+    return v.add(key, v.typeByName(key, typ), example, true)
+}
+
+// Optional declares a variable which must be parseable as typ if it is set.
+// See Require for the supported types.
+func (v *Validator) Optional(key, typ, example string) *Validator {
+    return v.add(key, v.typeByName(key, typ), example, false)
+}
+
+// RequireType is like Require but takes the type itself, so that every type
+// supported by GetFrom can be used, including types implementing
+// encoding.TextUnmarshaler, slices and maps.
+func (v *Validator) RequireType(key string, typ reflect.Type, example string) *Validator {
+    return v.add(key, typ, example, true)
+}
+
+// OptionalType is like Optional but takes the type itself. See RequireType.
+func (v *Validator) OptionalType(key string, typ reflect.Type, example string) *Validator {
+    return v.add(key, typ, example, false)
+}
+
+func (v *Validator) typeByName(key, name string) reflect.Type {
+    t, ok := lookupTypeName(name)
+    if !ok {
+        panic("Unsupported type \"" + name + "\" for environment variable \"" + v.env.prepareKey(key) + "\"")
+    }
+
+    return t
+}
+
+func (v *Validator) add(key string, typ reflect.Type, example string, required bool) *Validator {
+    if !canParse(typ) {
+        panic("Unsupported type \"" + typ.String() + "\" for environment variable \"" + v.env.prepareKey(key) + "\"")
+    }
+
+    v.checks = append(v.checks, check{key: key, typ: typ, example: example, required: required})
+    return v
+}
+
+// Validate checks all declared variables and returns a *ValidationError
+// describing every missing or malformed one, or nil if all are valid.
+func (v *Validator) Validate() error {
+    var problems []Problem
+    for _, c := range v.checks {
+        _, err := v.env.lookupValue(c.key, c.typ)
+        if err == nil || (!c.required && errors.Is(err, ErrNotSet)) {
+            continue
+        }
+
+        key := v.env.prepareKey(c.key)
+        problems = append(problems, Problem{Key: key, Type: c.typ.String(), Example: v.env.redactExample(key, c.example), Err: err})
+    }
+
+    if len(problems) > 0 {
+        return &ValidationError{Problems: problems}
+    }
+
+    return nil
+}
+
+// MustValidate calls Validate. On failure it writes the report to standard
+// error and exits the program with status 1.
+func (v *Validator) MustValidate() {
+    mustSucceed(v.Validate())
+}
+
+// MustProcess calls Process. On failure it writes the report to standard
+// error and exits the program with status 1.
+func (e *Env) MustProcess(spec interface{}) {
+    mustSucceed(e.Process(spec))
+}
+
+func mustSucceed(err error) {
+    if err == nil {
+        return
+    }
+
+    var verr *ValidationError
+    if errors.As(err, &verr) {
+        verr.WriteReport(stderr)
+    } else {
+        fmt.Fprintln(stderr, err)
+    }
+
+    exit(1)
+}
@@ -0,0 +1,147 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "bytes"
+    "errors"
+    "net"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PORT":    "http",
+        "APP_HOST":    "localhost",
+        "APP_RETRIES": "-1",
+    }))
+
+    v := e.NewValidator().
+        Require("port", "int", "8080").
+        Require("host", "string", "example.com").
+        Require("timeout", "time.Duration", "30s").
+        Optional("debug", "bool", "true").
+        Optional("retries", "uint", "3")
+
+    err := v.Validate()
+    var verr *ValidationError
+    if !assert.True(errors.As(err, &verr)) {
+        return
+    }
+
+    assert.Len(verr.Problems, 3)
+    assert.Equal("APP_PORT", verr.Problems[0].Key)
+    assert.Equal("int", verr.Problems[0].Type)
+    assert.Equal("8080", verr.Problems[0].Example)
+    assert.Equal("invalid value \"http\": invalid syntax", verr.Problems[0].Description())
+    assert.Equal("APP_TIMEOUT", verr.Problems[1].Key)
+    assert.Equal("not set", verr.Problems[1].Description())
+    assert.Equal("APP_RETRIES", verr.Problems[2].Key)
+
+    assert.True(errors.Is(err, ErrNotSet))
+    var perr *ParseError
+    assert.True(errors.As(err, &perr))
+    assert.Equal("APP_PORT", perr.Key)
+
+    assert.True(strings.HasPrefix(err.Error(), "3 environment variables are invalid: APP_PORT"))
+
+    report := verr.Report()
+    assert.Contains(report, "Invalid environment configuration")
+    assert.Contains(report, "APP_TIMEOUT")
+    assert.Contains(report, "time.Duration")
+    assert.Contains(report, "30s")
+
+    e.SetString("port", "8080")
+    e.SetString("timeout", "1m")
+    e.SetString("retries", "3")
+    assert.NoError(v.Validate())
+
+    assert.Panics(func() { e.NewValidator().Require("port", "complex128", "") })
+}
+
+func TestValidatorTypes(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_URL":   "http://[::1",
+        "APP_IP":    "10.0.0.300",
+        "APP_PORTS": "80,x",
+    }))
+
+    v := e.NewValidator().
+        Require("url", "*url.URL", "http://localhost").
+        RequireType("ip", reflect.TypeOf(net.IP{}), "10.0.0.1").
+        OptionalType("ports", reflect.TypeOf([]int{}), "80,443").
+        OptionalType("debug", reflect.TypeOf(true), "")
+
+    var verr *ValidationError
+    if assert.True(errors.As(v.Validate(), &verr)) && assert.Len(verr.Problems, 3) {
+        assert.Equal("*url.URL", verr.Problems[0].Type)
+        assert.Equal("APP_IP", verr.Problems[1].Key)
+        assert.Equal("net.IP", verr.Problems[1].Type)
+        assert.Equal("[]int", verr.Problems[2].Type)
+    }
+
+    e.SetString("url", "http://localhost")
+    e.SetString("ip", "10.0.0.1")
+    e.SetString("ports", "80,443")
+    assert.NoError(v.Validate())
+
+    assert.Panics(func() { e.NewValidator().RequireType("value", reflect.TypeOf(1i), "") })
+    assert.Panics(func() { e.NewValidator().OptionalType("values", reflect.TypeOf([][]int{}), "") })
+}
+
+func TestValidationErrorSingle(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    err := e.NewValidator().Require("port", "int", "").Validate()
+    assert.Equal("Environment variable \"APP_PORT\" not found", err.Error())
+}
+
+func TestMustValidate(t *testing.T) {
+    assert := assert.New(t)
+
+    var buf bytes.Buffer
+    code := -1
+    oldStderr, oldExit := stderr, exit
+    stderr, exit = &buf, func(c int) { code = c }
+    defer func() { stderr, exit = oldStderr, oldExit }()
+
+    e := New("app")
+    e.SetSource(NewMapSource(nil))
+
+    e.NewValidator().Optional("port", "int", "").MustValidate()
+    assert.Equal(-1, code)
+    assert.Empty(buf.String())
+
+    e.NewValidator().Require("port", "int", "").MustValidate()
+    assert.Equal(1, code)
+    assert.Contains(buf.String(), "APP_PORT")
+
+    var cfg struct {
+        Port int `required:"true" example:"8080"`
+        Host int `default:"localhost"`
+    }
+
+    buf.Reset()
+    code = -1
+    e.MustProcess(&cfg)
+    assert.Equal(1, code)
+    assert.Contains(buf.String(), "APP_PORT")
+    assert.Contains(buf.String(), "8080")
+    assert.Contains(buf.String(), "APP_HOST")
+    assert.Contains(buf.String(), "localhost")
+}
@@ -0,0 +1,272 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "reflect"
+    "time"
+)
+
+// Variable describes an environment variable registered with a VarSet.
+type Variable struct {
+    Name     string // name as registered
+    Key      string // normalized and prefixed key
+    Type     string // name of the Go type
+    Default  string // formatted default value
+    Usage    string // description of the variable
+    Required bool   // whether the variable must be set
+    Secret   bool   // whether the key is secret
+
+    value   reflect.Value // the variable the value is stored in
+    flagged bool          // whether the value was passed as flag
+}
+
+// VarSet is a set of registered environment variables, similar to
+// flag.FlagSet. Variables are registered with a default value and a usage
+// message and resolved at once by Parse, which gives an inventory of all
+// variables a program consumes.
+type VarSet struct {
+    env    *Env
+    vars   []*Variable
+    byName map[string]*Variable // by normalized name, without prefix
+    parsed bool
+}
+
+// NewVarSet returns an empty VarSet which resolves variables through e, or
+// through the default Env if e is nil.
+func NewVarSet(e *Env) *VarSet {
+    if e == nil {
+        e = std
+    }
+
+    return &VarSet{env: e, byName: make(map[string]*Variable)}
+}
+
+// DefaultVars is the VarSet used by the package level registration
+// functions.
+var DefaultVars = NewVarSet(nil)
+
+// DefineVar registers the variable name in s, storing its value in p. The
+// value pointed to by p is set to value, which is kept if the environment
+// variable is not set. DefineVar panics if name is already registered or T
+// is not supported.
+func DefineVar[T any](s *VarSet, p *T, name string, value T, usage string) {
+    key := s.env.prepareKey(name)
+    if _, ok := s.byName[s.env.keyFunc(name)]; ok {
+        panic("Environment variable \"" + key + "\" redefined")
+    }
+
+    v := reflect.ValueOf(p).Elem()
+    def, err := s.env.formatValue(reflect.ValueOf(value))
+    if err != nil {
+        panic("Can not format type " + v.Type().String() + " for environment variable \"" + key + "\"")
+    }
+
+    *p = value
+    variable := &Variable{
+        Name:    name,
+        Key:     key,
+        Type:    v.Type().String(),
+        Default: def,
+        Usage:   usage,
+        Secret:  v.Type() == typeOf[Secret](),
+        value:   v,
+    }
+    s.vars = append(s.vars, variable)
+    s.byName[s.env.keyFunc(name)] = variable
+}
+
+// Define registers the variable name in s and returns a pointer to its
+// value. See DefineVar for details.
+func Define[T any](s *VarSet, name string, value T, usage string) *T {
+    p := new(T)
+    DefineVar(s, p, name, value, usage)
+    return p
+}
+
+// MarkRequired marks the registered variable name as required, so that
+// Parse fails if it is not set.
+func (s *VarSet) MarkRequired(name string) error {
+    v, ok := s.byName[s.env.keyFunc(name)]
+    if !ok {
+        return errors.New("Environment variable \"" + s.env.prepareKey(name) + "\" is not registered")
+    }
+
+    v.Required = true
+    return nil
+}
+
+// Variables returns all registered variables in the order of registration.
+func (s *VarSet) Variables() []*Variable {
+    for _, v := range s.vars {
+        v.Key = s.env.prepareKey(v.Name)
+        v.Secret = v.Secret || s.env.isSecretKey(v.Key)
+    }
+
+    return s.vars
+}
+
+// Parse resolves all registered variables. Variables which are not set keep
+// their default value. All variables are resolved even if some of them
+// fail; the returned *ValidationError lists every missing required and
+// every malformed variable and every violated rule added with
+// Env.AddRules. Variables which fail keep their default value.
+func (s *VarSet) Parse() error {
+    s.parsed = true
+
+    var problems []Problem
+    for _, v := range s.Variables() {
+        if v.flagged {
+            continue
+        }
+        if v.Secret {
+            s.env.MarkSecret(v.Name)
+        }
+
+        problem := Problem{Key: v.Key, Type: v.Type, Example: s.env.redactExample(v.Key, v.Default)}
+
+        str, err := s.env.lookup(v.Name)
+        if errors.Is(err, ErrNotSet) {
+            if v.Required {
+                problem.Err = err
+                problems = append(problems, problem)
+            }
+            continue
+        }
+        if err != nil {
+            problem.Err = err
+            problems = append(problems, problem)
+            continue
+        }
+
+        value, err := s.env.parseValue(v.value.Type(), str)
+        if err != nil {
+            problem.Err = s.env.parseError(v.Key, str, v.Type, err)
+            problems = append(problems, problem)
+            continue
+        }
+        if err := s.env.checkRules(v.Key, str, value, nil); err != nil {
+            problem.Err = err
+            problems = append(problems, problem)
+            continue
+        }
+        v.value.Set(value)
+    }
+
+    if len(problems) > 0 {
+        return &ValidationError{Problems: problems}
+    }
+
+    return nil
+}
+
+// MustParse calls Parse. On failure it writes the report to standard error
+// and exits the program with status 1.
+func (s *VarSet) MustParse() {
+    mustSucceed(s.Parse())
+}
+
+// Parsed reports whether Parse has been called.
+func (s *VarSet) Parsed() bool {
+    return s.parsed
+}
+
+// String registers a string variable.
+func (s *VarSet) String(name string, value string, usage string) *string {
+    return Define(s, name, value, usage)
+}
+
+// Bool registers a bool variable.
+func (s *VarSet) Bool(name string, value bool, usage string) *bool {
+    return Define(s, name, value, usage)
+}
+
+// Duration registers a time.Duration variable.
+func (s *VarSet) Duration(name string, value time.Duration, usage string) *time.Duration {
+    return Define(s, name, value, usage)
+}
+
+// Float64 registers a float64 variable.
+func (s *VarSet) Float64(name string, value float64, usage string) *float64 {
+    return Define(s, name, value, usage)
+}
+
+// Int registers an int variable.
+func (s *VarSet) Int(name string, value int, usage string) *int {
+    return Define(s, name, value, usage)
+}
+
+// Int64 registers an int64 variable.
+func (s *VarSet) Int64(name string, value int64, usage string) *int64 {
+    return Define(s, name, value, usage)
+}
+
+// UInt registers a uint variable.
+func (s *VarSet) UInt(name string, value uint, usage string) *uint {
+    return Define(s, name, value, usage)
+}
+
+// UInt64 registers a uint64 variable.
+func (s *VarSet) UInt64(name string, value uint64, usage string) *uint64 {
+    return Define(s, name, value, usage)
+}
+
+// String registers a string variable in DefaultVars.
+func String(name string, value string, usage string) *string {
+    return DefaultVars.String(name, value, usage)
+}
+
+// Bool registers a bool variable in DefaultVars.
+func Bool(name string, value bool, usage string) *bool {
+    return DefaultVars.Bool(name, value, usage)
+}
+
+// Duration registers a time.Duration variable in DefaultVars.
+func Duration(name string, value time.Duration, usage string) *time.Duration {
+    return DefaultVars.Duration(name, value, usage)
+}
+
+// Float64 registers a float64 variable in DefaultVars.
+func Float64(name string, value float64, usage string) *float64 {
+    return DefaultVars.Float64(name, value, usage)
+}
+
+// Int registers an int variable in DefaultVars.
+func Int(name string, value int, usage string) *int {
+    return DefaultVars.Int(name, value, usage)
+}
+
+// Int64 registers an int64 variable in DefaultVars.
+func Int64(name string, value int64, usage string) *int64 {
+    return DefaultVars.Int64(name, value, usage)
+}
+
+// UInt registers a uint variable in DefaultVars.
+func UInt(name string, value uint, usage string) *uint {
+    return DefaultVars.UInt(name, value, usage)
+}
+
+// UInt64 registers a uint64 variable in DefaultVars.
+func UInt64(name string, value uint64, usage string) *uint64 {
+    return DefaultVars.UInt64(name, value, usage)
+}
+
+// Parse resolves all variables registered in DefaultVars.
+// See VarSet.Parse for details.
+func Parse() error {
+    return DefaultVars.Parse()
+}
+
+// MustParse resolves all variables registered in DefaultVars. On failure it
+// writes the report to standard error and exits the program with status 1.
+func MustParse() {
+    DefaultVars.MustParse()
+}
+
+// Parsed reports whether Parse has been called on DefaultVars.
+func Parsed() bool {
+    return DefaultVars.Parsed()
+}
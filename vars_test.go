@@ -0,0 +1,162 @@
+// Copyright 2016 Stefan Böhmann.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package envconf
+
+import (
+    "errors"
+    "net/url"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestVarSet(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_HOST":    "example.com",
+        "APP_PORT":    "9090",
+        "APP_DEBUG":   "yes",
+        "APP_TIMEOUT": "1m",
+    }))
+
+    s := NewVarSet(e)
+    host := s.String("host", "localhost", "listen address")
+    port := s.Int("port", 8080, "listen port")
+    debug := s.Bool("debug", false, "enable debug output")
+    timeout := s.Duration("timeout", 5*time.Second, "request timeout")
+    ratio := s.Float64("ratio", 0.5, "sampling ratio")
+    size := s.Int64("max size", 1024, "")
+    workers := s.UInt("workers", 4, "")
+    limit := s.UInt64("limit", 100, "")
+    tags := Define(s, "tags", []string{"a", "b"}, "")
+
+    assert.Equal("localhost", *host)
+    assert.Equal(8080, *port)
+    assert.False(s.Parsed())
+
+    assert.NoError(s.Parse())
+    assert.True(s.Parsed())
+    assert.Equal("example.com", *host)
+    assert.Equal(9090, *port)
+    assert.True(*debug)
+    assert.Equal(time.Minute, *timeout)
+    assert.Equal(0.5, *ratio)
+    assert.Equal(int64(1024), *size)
+    assert.Equal(uint(4), *workers)
+    assert.Equal(uint64(100), *limit)
+    assert.Equal([]string{"a", "b"}, *tags)
+
+    vars := s.Variables()
+    assert.Len(vars, 9)
+    assert.Equal("port", vars[1].Name)
+    assert.Equal("APP_PORT", vars[1].Key)
+    assert.Equal("int", vars[1].Type)
+    assert.Equal("8080", vars[1].Default)
+    assert.Equal("listen port", vars[1].Usage)
+    assert.False(vars[1].Required)
+    assert.False(vars[1].Secret)
+    assert.Equal("APP_MAX_SIZE", vars[5].Key)
+    assert.Equal("time.Duration", vars[3].Type)
+    assert.Equal("a,b", vars[8].Default)
+}
+
+func TestVarSetErrors(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("app")
+    e.SetSource(NewMapSource(map[string]string{
+        "APP_PORT":     "http",
+        "APP_TIMEOUT":  "soon",
+        "APP_PASSWORD": "hunter2",
+    }))
+
+    s := NewVarSet(e)
+    port := s.Int("port", 8080, "")
+    timeout := s.Duration("timeout", time.Second, "")
+    name := s.String("name", "", "")
+    password := Define(s, "password", 0, "")
+    assert.NoError(s.MarkRequired("name"))
+    assert.Error(s.MarkRequired("missing"))
+
+    err := s.Parse()
+    var verr *ValidationError
+    assert.True(errors.As(err, &verr))
+    assert.Len(verr.Problems, 4)
+    assert.Equal("APP_PORT", verr.Problems[0].Key)
+    assert.Equal("8080", verr.Problems[0].Example)
+    assert.Equal("APP_TIMEOUT", verr.Problems[1].Key)
+    assert.Equal("APP_NAME", verr.Problems[2].Key)
+    assert.True(errors.Is(verr.Problems[2].Err, ErrNotSet))
+    assert.Equal("APP_PASSWORD", verr.Problems[3].Key)
+    assert.NotContains(err.Error(), "hunter2")
+
+    assert.Equal(8080, *port)
+    assert.Equal(time.Second, *timeout)
+    assert.Equal("", *name)
+    assert.Equal(0, *password)
+}
+
+func TestVarSetSecret(t *testing.T) {
+    assert := assert.New(t)
+
+    e := New("")
+    e.SetSource(NewMapSource(map[string]string{"API_KEY": "hunter2"}))
+
+    s := NewVarSet(e)
+    key := Define(s, "api key", Secret(""), "")
+    assert.NoError(s.Parse())
+    assert.Equal("hunter2", key.Value())
+    assert.True(e.IsSecret("api key"))
+    assert.True(s.Variables()[0].Secret)
+
+    Define(s, "db password", Secret("changeme"), "")
+    assert.NoError(s.MarkRequired("db password"))
+    var verr *ValidationError
+    if assert.True(errors.As(s.Parse(), &verr)) {
+        assert.Equal(Redacted, verr.Problems[0].Example)
+        assert.NotContains(verr.Report(), "changeme")
+    }
+}
+
+func TestVarSetPanics(t *testing.T) {
+    assert := assert.New(t)
+
+    s := NewVarSet(New(""))
+    s.Int("port", 0, "")
+    assert.Panics(func() { s.String("PORT", "", "") })
+    assert.Panics(func() { Define(s, "url", struct{}{}, "") })
+    assert.NotPanics(func() { Define(s, "url", &url.URL{}, "") })
+
+    // Registered names are independent of the prefix
+    e := New("")
+    s = NewVarSet(e)
+    s.Int("port", 0, "")
+    e.SetPrefix("app")
+    assert.NoError(s.MarkRequired("port"))
+    assert.Equal("APP_PORT", s.Variables()[0].Key)
+    assert.Panics(func() { s.Int("port", 0, "") })
+    assert.NotPanics(func() { s.Int("app port", 0, "") })
+}
+
+func TestDefaultVars(t *testing.T) {
+    assert := assert.New(t)
+
+    old := DefaultVars
+    defer func() { DefaultVars = old }()
+    DefaultVars = NewVarSet(nil)
+    assert.Equal(std, DefaultVars.env)
+
+    t.Setenv("ENVCONF_VARS_PORT", "9090")
+    port := Int("envconf_vars_port", 8080, "")
+    host := String("envconf_vars_host", "localhost", "")
+    assert.False(Parsed())
+    assert.NoError(Parse())
+    assert.True(Parsed())
+    assert.Equal(9090, *port)
+    assert.Equal("localhost", *host)
+}